@@ -0,0 +1,89 @@
+package invalid
+
+import (
+	"errors"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"strconv"
+)
+
+const (
+	ConstraintKeyMinItems = `$min-items` //minimum number of items, valid under type $arr
+	ConstraintKeyMaxItems = `$max-items` //maximum number of items, valid under type $arr
+	ConstraintKeyMinKeys  = `$min-keys`  //minimum number of keys, valid under type $obj
+	ConstraintKeyMaxKeys  = `$max-keys`  //maximum number of keys, valid under type $obj
+)
+
+// cardinality represent bounds of number of children in a collection, both of them are inclusive
+type cardinality struct {
+	min *int
+	max *int
+}
+
+// newCardinality create cardinality from constraints minKey and maxKey of rule node, nil is returned if neither exists
+func newCardinality(key string, node *yaml.Node, minKey, maxKey string) (*cardinality, error) {
+	c := &cardinality{}
+	for _, b := range []struct {
+		name  string
+		bound **int
+	}{
+		{minKey, &c.min},
+		{maxKey, &c.max},
+	} {
+		k, v, e := GetKVNodeByKeyName(b.name, node.Content)
+		if !(k != nil && v != nil && e) {
+			continue
+		}
+		if !validIntNode(v) {
+			return nil, errors.New(fmt.Sprintf("value of %s must be int : [%s]", b.name, key))
+		}
+		n, err := strconv.Atoi(v.Value)
+		if err != nil || n < 0 {
+			return nil, errors.New(fmt.Sprintf("value of %s must be a non-negative int : [%s]", b.name, key))
+		}
+		*b.bound = &n
+	}
+
+	if c.min == nil && c.max == nil {
+		return nil, nil
+	}
+	if c.min != nil && c.max != nil && *c.min > *c.max {
+		return nil, errors.New(fmt.Sprintf("%s is greater than %s : [%s]", minKey, maxKey, key))
+	}
+	return c, nil
+}
+
+// check return the bound which count violates, empty string is returned if count is in bounds
+func (c *cardinality) check(count int) string {
+	if c.min != nil && count < *c.min {
+		return fmt.Sprintf("at least %d", *c.min)
+	}
+	if c.max != nil && count > *c.max {
+		return fmt.Sprintf("at most %d", *c.max)
+	}
+	return ""
+}
+
+// validateItemCount check number of items in sequence field f
+func (rule *ArrRule) validateItemCount(f Field, result *[]*Result) *[]*Result {
+	if rule.itemCount == nil || f.Kind() != FieldKindSequence {
+		return result
+	}
+	if bound := rule.itemCount.check(len(f.Fields())); bound != "" {
+		result = appendResult(result, ItemCountMismatch, NewItemCountError(f.Key(), len(f.Fields()), bound),
+			f.getValueRange())
+	}
+	return result
+}
+
+// validateKeyCount check number of keys in mapping field f
+func (rule *ObjRule) validateKeyCount(f Field, result *[]*Result) *[]*Result {
+	if rule.keys == nil || f.Kind() != FieldKindMapping {
+		return result
+	}
+	if bound := rule.keys.check(len(f.Fields())); bound != "" {
+		result = appendResult(result, KeyCountMismatch, NewKeyCountError(f.Key(), len(f.Fields()), bound),
+			f.getValueRange())
+	}
+	return result
+}
@@ -0,0 +1,363 @@
+package invalid
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"strings"
+)
+
+const (
+	ConstraintKeyIf         = `$if`         //tests of sibling fields, valid under type $obj
+	ConstraintKeyThen       = `$then`       //rules applied while tests in $if pass, valid alongside $if
+	ConstraintKeyElse       = `$else`       //rules applied while tests in $if fail, valid alongside $if
+	ConstraintKeyConditions = `$conditions` //a list of conditional blocks with $if, valid under type $obj
+	ConstraintKeyEq         = `$eq`         //field must be equal to the value, valid under $if
+	ConstraintKeyExists     = `$exists`     //field must exist or not, valid under $if
+	ConstraintKeyForbidden  = `$forbidden`  //field must not exist, valid under $then and $else
+)
+
+// condition represent a conditional block of $obj
+type condition struct {
+	tests []*conditionTest
+	then  *conditionBranch
+	els   *conditionBranch
+}
+
+// conditionTest represent a test of a sibling field in $if
+type conditionTest struct {
+	key    string
+	eq     *yaml.Node
+	of     []*yaml.Node
+	exists *bool
+}
+
+// conditionBranch represent rules in $then or $else
+type conditionBranch struct {
+	rules     []Ruler         //additional rules of fields
+	optional  map[string]bool //$optional of fields overridden
+	forbidden []string        //fields must not exist
+}
+
+// activeBranch represent a branch applied to a field and the condition which triggered it
+type activeBranch struct {
+	*conditionBranch
+	condition string
+}
+
+// optionalOverride represent $optional of a field overridden by a condition
+type optionalOverride struct {
+	optional  bool
+	condition string
+}
+
+// newConditions create conditional blocks declared by $if or $conditions in rule node
+func newConditions(key string, node *yaml.Node, scope *ruleScope) ([]*condition, error) {
+	blocks := make([]*yaml.Node, 0)
+	if k, _, e := GetKVNodeByKeyName(ConstraintKeyIf, node.Content); k != nil && e {
+		blocks = append(blocks, node)
+	}
+	if k, v, e := GetKVNodeByKeyName(ConstraintKeyConditions, node.Content); k != nil && v != nil && e {
+		if !validArrNode(v) {
+			return nil, ConstraintTypeError(ConstraintKeyConditions, yamlNodeTypeSeq)
+		}
+		blocks = append(blocks, v.Content...)
+	}
+
+	conditions := make([]*condition, 0, len(blocks))
+	for _, block := range blocks {
+		c, err := newCondition(key, block, scope)
+		if err != nil {
+			return nil, err
+		}
+		conditions = append(conditions, c)
+	}
+	return conditions, nil
+}
+
+func newCondition(key string, block *yaml.Node, scope *ruleScope) (*condition, error) {
+	if !validMapNode(block) {
+		return nil, ConstraintTypeError(ConstraintKeyConditions, yamlNodeTypeMap)
+	}
+	_, v, _ := getKVNodeInMap(ConstraintKeyIf, block.Content)
+	if v == nil || !validMapNode(v) {
+		return nil, errors.New(fmt.Sprintf("%s of map is required in conditional block : [%s]", ConstraintKeyIf, key))
+	}
+
+	c := &condition{}
+	for i := 0; i < len(v.Content)/2; i++ {
+		t, err := newConditionTest(v.Content[i*2], v.Content[i*2+1])
+		if err != nil {
+			return nil, err
+		}
+		c.tests = append(c.tests, t)
+	}
+
+	var err error
+	if _, then, _ := getKVNodeInMap(ConstraintKeyThen, block.Content); then != nil {
+		c.then, err = newConditionBranch(key, ConstraintKeyThen, then, scope)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if _, els, _ := getKVNodeInMap(ConstraintKeyElse, block.Content); els != nil {
+		c.els, err = newConditionBranch(key, ConstraintKeyElse, els, scope)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if c.then == nil && c.els == nil {
+		return nil, errors.New(fmt.Sprintf("%s or %s is required alongside %s : [%s]", ConstraintKeyThen,
+			ConstraintKeyElse, ConstraintKeyIf, key))
+	}
+	return c, nil
+}
+
+// newConditionTest create test of field k, a scalar value is a shorthand of $eq
+func newConditionTest(k, v *yaml.Node) (*conditionTest, error) {
+	t := &conditionTest{key: k.Value}
+	if v.Kind == yaml.ScalarNode {
+		t.eq = v
+		return t, nil
+	}
+	if !validMapNode(v) {
+		return nil, errors.New(fmt.Sprintf("test of [%s] in %s must be scalar or map", k.Value, ConstraintKeyIf))
+	}
+
+	for i := 0; i < len(v.Content)/2; i++ {
+		tk, tv := v.Content[i*2], v.Content[i*2+1]
+		switch tk.Value {
+		case ConstraintKeyEq:
+			if tv.Kind != yaml.ScalarNode {
+				return nil, errors.New(fmt.Sprintf("value of %s must be scalar : [%s]", ConstraintKeyEq, k.Value))
+			}
+			t.eq = tv
+		case ConstraintKeyOf:
+			if !validArrNode(tv) {
+				return nil, ConstraintTypeError(k.Value, yamlNodeTypeSeq)
+			}
+			t.of = tv.Content
+		case ConstraintKeyExists:
+			if !validBoolNode(tv) {
+				return nil, errors.New(fmt.Sprintf("value node must be boolean : [%s]", tk.Value))
+			}
+			exists := tv.Value == "true"
+			t.exists = &exists
+		default:
+			return nil, errors.New(fmt.Sprintf("unknown test [%s] of [%s] in %s", tk.Value, k.Value, ConstraintKeyIf))
+		}
+	}
+	if t.eq == nil && t.of == nil && t.exists == nil {
+		return nil, errors.New(fmt.Sprintf("test of [%s] in %s is empty", k.Value, ConstraintKeyIf))
+	}
+	return t, nil
+}
+
+// newConditionBranch create branch from node of $then or $else, an entry with $type or $use is an additional rule,
+// otherwise it overrides $optional of the field or forbids it.
+func newConditionBranch(key, name string, node *yaml.Node, scope *ruleScope) (*conditionBranch, error) {
+	if !validMapNode(node) {
+		return nil, ConstraintTypeError(name, yamlNodeTypeMap)
+	}
+
+	branch := &conditionBranch{optional: map[string]bool{}}
+	for i := 0; i < len(node.Content)/2; i++ {
+		k, v := node.Content[i*2], node.Content[i*2+1]
+		if !validMapNode(v) {
+			return nil, ConstraintTypeError(fmt.Sprintf("%s.%s", name, k.Value), yamlNodeTypeMap)
+		}
+
+		_, t, _ := getKVNodeInMap(ConstraintKeyType, v.Content)
+		_, u, _ := getKVNodeInMap(ConstraintKeyUse, v.Content)
+		if t != nil || u != nil {
+			r, err := newRuler(k, v, false, scope)
+			if err != nil {
+				return nil, err
+			}
+			err = r.restructure()
+			if err != nil {
+				return nil, err
+			}
+			err = checkDefault(r)
+			if err != nil {
+				return nil, err
+			}
+			branch.rules = append(branch.rules, r)
+			continue
+		}
+
+		for j := 0; j < len(v.Content)/2; j++ {
+			ck, cv := v.Content[j*2], v.Content[j*2+1]
+			if !validBoolNode(cv) {
+				return nil, errors.New(fmt.Sprintf("value node must be boolean : [%s]", ck.Value))
+			}
+			switch ck.Value {
+			case ConstraintKeyOptional:
+				branch.optional[k.Value] = cv.Value == "true"
+			case ConstraintKeyForbidden:
+				if cv.Value == "true" {
+					branch.forbidden = append(branch.forbidden, k.Value)
+				}
+			default:
+				return nil, errors.New(fmt.Sprintf("only %s or %s is allowed without %s : [%s.%s.%s]",
+					ConstraintKeyOptional, ConstraintKeyForbidden, ConstraintKeyType, key, name, k.Value))
+			}
+		}
+	}
+	return branch, nil
+}
+
+// match check whether sibling field of test under mapping field f pass the test
+func (t *conditionTest) match(f Field) bool {
+	field, exist := f.Get(t.key)
+	if t.exists != nil && *t.exists != exist {
+		return false
+	}
+	if t.eq != nil && !(exist && scalarEqual(t.eq, field)) {
+		return false
+	}
+	if t.of != nil {
+		if !exist {
+			return false
+		}
+		for _, n := range t.of {
+			if scalarEqual(n, field) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+func (t *conditionTest) String() string {
+	tests := make([]string, 0)
+	if t.exists != nil {
+		if *t.exists {
+			tests = append(tests, fmt.Sprintf("%s exists", t.key))
+		} else {
+			tests = append(tests, fmt.Sprintf("%s not exists", t.key))
+		}
+	}
+	if t.eq != nil {
+		tests = append(tests, fmt.Sprintf("%s == %s", t.key, t.eq.Value))
+	}
+	if t.of != nil {
+		of := make([]string, 0, len(t.of))
+		for _, n := range t.of {
+			of = append(of, n.Value)
+		}
+		tests = append(tests, fmt.Sprintf("%s in %v", t.key, of))
+	}
+	return strings.Join(tests, " && ")
+}
+
+// match check whether all tests of condition pass under mapping field f
+func (c *condition) match(f Field) bool {
+	for _, t := range c.tests {
+		if !t.match(f) {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *condition) String() string {
+	tests := make([]string, 0, len(c.tests))
+	for _, t := range c.tests {
+		tests = append(tests, t.String())
+	}
+	return strings.Join(tests, " && ")
+}
+
+// scalarEqual check whether scalar node n is equal to field f in both value and type
+func scalarEqual(n *yaml.Node, f Field) bool {
+	return f.Kind() == FieldKindScalar && n.Tag == f.Tag() && n.Value == f.Value()
+}
+
+// activeBranches return branches applied to mapping field f in declared order
+func (rule *ObjRule) activeBranches(f Field) []*activeBranch {
+	branches := make([]*activeBranch, 0)
+	for _, c := range rule.conditions {
+		if c.match(f) {
+			if c.then != nil {
+				branches = append(branches, &activeBranch{conditionBranch: c.then, condition: c.String()})
+			}
+		} else if c.els != nil {
+			branches = append(branches, &activeBranch{conditionBranch: c.els, condition: fmt.Sprintf("not (%s)", c)})
+		}
+	}
+	return branches
+}
+
+// declaredInConditions check whether key is declared in any branch of conditions
+func (rule *ObjRule) declaredInConditions(key string) bool {
+	for _, c := range rule.conditions {
+		for _, b := range []*conditionBranch{c.then, c.els} {
+			if b == nil {
+				continue
+			}
+			if _, exist := b.optional[key]; exist {
+				return true
+			}
+			for _, r := range b.rules {
+				if r.Key() == key {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// optionalOverrides return $optional of fields overridden by branches, the latter one wins
+func optionalOverrides(branches []*activeBranch) map[string]*optionalOverride {
+	overrides := map[string]*optionalOverride{}
+	for _, b := range branches {
+		for k, optional := range b.optional {
+			overrides[k] = &optionalOverride{optional: optional, condition: b.condition}
+		}
+	}
+	return overrides
+}
+
+// validateBranches validate mapping field f against forbidden fields and additional rules of branches
+func validateBranches(ctx context.Context, cancel context.CancelFunc, f Field, branches []*activeBranch,
+	result *[]*Result) *[]*Result {
+	for _, b := range branches {
+		for _, k := range b.forbidden {
+			if child, exist := f.Get(k); exist {
+				result = appendResult(result, KeyForbidden, NewConditionalError(NewKeyForbiddenError(k), b.condition),
+					child.KeyRange())
+			}
+		}
+
+		for _, r := range b.rules {
+			if ctx.Err() == context.Canceled {
+				return result
+			}
+			child, exist := f.Get(r.Key())
+			if !exist {
+				if r.Required() {
+					result = appendResult(result, KeyMissing, NewConditionalError(NewKeyMissingError(r.Key()), b.condition),
+						f.getValueRange())
+					cancel()
+					return result
+				}
+				continue
+			}
+			for _, x := range *validateRule(ctx, cancel, r, child, nil) {
+				x.Error = NewConditionalError(x.Error, b.condition)
+				y := append(*result, x)
+				result = &y
+			}
+		}
+	}
+	return result
+}
+
+// NewConditionalError wrap err with the condition which triggered it
+func NewConditionalError(err error, condition string) error {
+	return errors.New(fmt.Sprintf("%s when [%s]", err.Error(), condition))
+}
@@ -0,0 +1,63 @@
+package invalid
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+const (
+	ConstraintKeyContains    = `$contains`     //rule some items must match under type $arr, or substring under type $str
+	ConstraintKeyMinContains = `$min-contains` //minimum number of items matching $contains, 1 by default
+	ConstraintKeyMaxContains = `$max-contains` //maximum number of items matching $contains
+)
+
+// newContains read rule of $contains and bounds of number of items matching it
+func (rule *ArrRule) newContains() error {
+	k, v, e := GetKVNodeByKeyName(ConstraintKeyContains, rule.getContent())
+	if !(k != nil && v != nil && e) {
+		for _, name := range []string{ConstraintKeyMinContains, ConstraintKeyMaxContains} {
+			if k, _, e := GetKVNodeByKeyName(name, rule.getContent()); k != nil && e {
+				return errors.New(fmt.Sprintf("%s is valid alongside %s only : [%s]", name, ConstraintKeyContains,
+					rule.Key()))
+			}
+		}
+		return nil
+	}
+
+	r, err := newItemRule(fmt.Sprintf("%s.%s", rule.Key(), ConstraintKeyContains), v, rule.scope)
+	if err != nil {
+		return err
+	}
+	rule.contains = r
+
+	rule.containsCount, err = newCardinality(rule.Key(), rule.valueNode, ConstraintKeyMinContains,
+		ConstraintKeyMaxContains)
+	if err != nil {
+		return err
+	}
+	if rule.containsCount == nil {
+		min := 1
+		rule.containsCount = &cardinality{min: &min}
+	}
+	return nil
+}
+
+// validateContains check number of items in sequence field f matching rule of $contains,
+// other items are not constrained by it
+func (rule *ArrRule) validateContains(ctx context.Context, f Field, result *[]*Result) *[]*Result {
+	if rule.contains == nil || f.Kind() != FieldKindSequence {
+		return result
+	}
+
+	count := 0
+	for _, item := range f.Fields() {
+		if matchRule(ctx, rule.contains, item) {
+			count++
+		}
+	}
+	if bound := rule.containsCount.check(count); bound != "" {
+		result = appendResult(result, ContainsMismatch, NewContainsError(f.Key(), count, bound), f.getValueRange())
+	}
+	return result
+}
@@ -0,0 +1,175 @@
+package invalid
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	ConstraintKeyDefault = `$default` //default value of optional field, which is validated against the rule itself
+)
+
+// checkDefault validate default value of rule r against r itself
+func checkDefault(r Ruler) error {
+	def := r.getDefault()
+	if def == nil {
+		return nil
+	}
+
+	keyNode := &yaml.Node{Kind: yaml.ScalarNode, Tag: yamlNodeTypeStr, Value: r.Key()}
+	f, err := NewYamlField(keyNode, def)
+	if err != nil {
+		return err
+	}
+	if f == nil {
+		return NewDefaultError(r.Key(), errors.New("value is not supported"))
+	}
+	err = f.restructure(nil)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	result := validateRule(ctx, cancel, r, f, nil)
+	if len(*result) > 0 {
+		return NewDefaultError(r.Key(), (*result)[0].Error)
+	}
+	return nil
+}
+
+// ApplyDefaults return a copy of field f with missing optional keys filled in by $default of rule,
+// defaults of fields inside a default value are filled in as well. f is not modified.
+func ApplyDefaults(rule Ruler, f Field) (Field, error) {
+	node := copyNode(f.getValueNode(), false)
+	applyDefaults(rule, node, map[Ruler]bool{})
+
+	field, err := NewYamlField(nil, node)
+	if err != nil {
+		return nil, err
+	}
+	err = field.restructure(nil)
+	if err != nil {
+		return nil, err
+	}
+	return field, nil
+}
+
+// MarshalYAML emit field f in YAML, eg,. a field with defaults applied
+func MarshalYAML(f Field) ([]byte, error) {
+	buffer := &bytes.Buffer{}
+	encoder := yaml.NewEncoder(buffer)
+	encoder.SetIndent(2)
+	err := encoder.Encode(f.getValueNode())
+	if err != nil {
+		return nil, err
+	}
+	err = encoder.Close()
+	if err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// applyDefaults fill missing optional keys under node by rule recursively, rules in inserting are those whose
+// default is being filled in, which are not applied again inside their own default, eg,. children of a recursive
+// definition.
+func applyDefaults(rule Ruler, node *yaml.Node, inserting map[Ruler]bool) {
+	switch r := rule.(type) {
+	case *RefRule:
+		applyDefaults(r.target, node, inserting)
+	case *ObjRule:
+		if !validMapNode(node) {
+			return
+		}
+		for _, child := range r.GetRules() {
+			applyChildDefault(child, node, inserting)
+		}
+
+		//rules of branches are applied by conditions tested against the object with defaults above
+		if len(r.conditions) > 0 {
+			if f, err := NewYamlField(nil, node); err == nil && f.restructure(nil) == nil {
+				for _, b := range r.activeBranches(f) {
+					for _, child := range b.rules {
+						applyChildDefault(child, node, inserting)
+					}
+				}
+			}
+		}
+		for i := 0; i < len(node.Content)/2; i++ {
+			if p, matched := r.GetPatternRule(node.Content[i*2].Value); matched {
+				applyDefaults(p, node.Content[i*2+1], inserting)
+			}
+		}
+	case *ArrRule:
+		if !validArrNode(node) {
+			return
+		}
+		//missing optional items at the end are filled in by defaults in order
+		filled := len(node.Content)
+		for i := len(node.Content); i < len(r.items) && r.items[i].getDefault() != nil; i++ {
+			if inserting[r.items[i]] {
+				break
+			}
+			node.Content = append(node.Content, copyNode(r.items[i].getDefault(), true))
+		}
+		for i, item := range node.Content {
+			if c, ok := r.constraint.(Ruler); ok {
+				applyDefaults(c, item, inserting)
+			} else if i >= filled {
+				insertDefault(r.items[i], item, inserting)
+			} else if i < len(r.items) {
+				applyDefaults(r.items[i], item, inserting)
+			} else if r.items != nil && r.additionalItems != nil {
+				applyDefaults(r.additionalItems, item, inserting)
+			}
+		}
+	}
+}
+
+// applyChildDefault fill key of rule child under mapping node by its default if it's missing, and defaults
+// inside value of the key
+func applyChildDefault(child Ruler, node *yaml.Node, inserting map[Ruler]bool) {
+	_, v, _ := getKVNodeInMap(child.Key(), node.Content)
+	if v != nil {
+		applyDefaults(child, v, inserting)
+		return
+	}
+
+	def := child.getDefault()
+	if def == nil || inserting[child] {
+		return
+	}
+	v = copyNode(def, true)
+	node.Content = append(node.Content,
+		&yaml.Node{Kind: yaml.ScalarNode, Tag: yamlNodeTypeStr, Value: child.Key()}, v)
+	insertDefault(child, v, inserting)
+}
+
+// insertDefault fill defaults inside value v, which is default of rule r just filled in
+func insertDefault(r Ruler, v *yaml.Node, inserting map[Ruler]bool) {
+	inserting[r] = true
+	applyDefaults(r, v, inserting)
+	delete(inserting, r)
+}
+
+// copyNode return a deep copy of node, position of nodes is cleared while clearPosition is true,
+// eg,. nodes copied from rule file.
+func copyNode(node *yaml.Node, clearPosition bool) *yaml.Node {
+	n := *node
+	if clearPosition {
+		n.Line, n.Column = 0, 0
+	}
+	n.Content = make([]*yaml.Node, 0, len(node.Content))
+	for _, c := range node.Content {
+		n.Content = append(n.Content, copyNode(c, clearPosition))
+	}
+	return &n
+}
+
+func NewDefaultError(key string, err error) error {
+	return errors.New(fmt.Sprintf("default value of [%s] is invalid : %s", key, err.Error()))
+}
@@ -0,0 +1,169 @@
+package invalid
+
+import (
+	"errors"
+	"fmt"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	ConstraintKeyDefinitions = `$definitions` //named rules declared at top level of rule file, referenced by $use
+	ConstraintKeyUse         = `$use`         //reference to a named rule in $definitions, valid anywhere a rule is expected
+)
+
+// definition represent a named rule in $definitions
+type definition struct {
+	file      string //name of rule file which the definition is declared in
+	keyNode   *yaml.Node
+	valueNode *yaml.Node
+}
+
+// ruleScope holds the state shared by all rules while compiling a rule file and files it imports
+type ruleScope struct {
+	names       []string //names of definitions in declared order
+	definitions map[string]*definition
+	compiled    map[string]Ruler
+	resolver    Resolver        //resolver of rule files imported by $import
+	importing   []string        //rule files being imported, from the entry file to the current one
+	imported    map[string]bool //rule files imported already
+}
+
+func newRuleScope(resolver Resolver) *ruleScope {
+	return &ruleScope{
+		names:       make([]string, 0),
+		definitions: map[string]*definition{},
+		compiled:    map[string]Ruler{},
+		resolver:    resolver,
+		importing:   make([]string, 0),
+		imported:    map[string]bool{},
+	}
+}
+
+// addDefinitions add definitions declared under $definitions of document node in rule file
+func (scope *ruleScope) addDefinitions(file string, document *yaml.Node) error {
+	k, v, e := GetKVNodeByKeyName(ConstraintKeyDefinitions, document.Content)
+	if !(k != nil && v != nil && e) {
+		return nil
+	}
+	if !validMapNode(v) {
+		return ConstraintTypeError(ConstraintKeyDefinitions, yamlNodeTypeMap)
+	}
+
+	for i := 0; i < len(v.Content)/2; i++ {
+		key := v.Content[i*2]
+		value := v.Content[i*2+1]
+		if def, exist := scope.definitions[key.Value]; exist {
+			return errors.New(fmt.Sprintf("definition is declared more than once : [%s] in [%s] and [%s]",
+				key.Value, def.file, file))
+		}
+		scope.names = append(scope.names, key.Value)
+		scope.definitions[key.Value] = &definition{file: file, keyNode: key, valueNode: value}
+	}
+	return nil
+}
+
+// compile compile all definitions, so errors inside definitions are reported even if they are not referenced
+func (scope *ruleScope) compile() error {
+	for _, name := range scope.names {
+		_, err := scope.resolve(name)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resolve return the rule of definition by name, definition is compiled only once
+func (scope *ruleScope) resolve(name string) (Ruler, error) {
+	if r, exist := scope.compiled[name]; exist {
+		return r, nil
+	}
+	def, exist := scope.definitions[name]
+	if !exist {
+		return nil, errors.New(fmt.Sprintf("definition not found : [%s]", name))
+	}
+
+	r, err := newRuler(def.keyNode, def.valueNode, false, scope)
+	if err != nil {
+		return nil, err
+	}
+	//rule is registered before restructure, so that definition is able to reference itself
+	scope.compiled[name] = r
+	err = r.restructure()
+	if err != nil {
+		return nil, err
+	}
+	//default is declared alongside $use, where the field is optional
+	if r.getDefault() != nil {
+		return nil, errors.New(fmt.Sprintf("%s is not applicable to definition, declare it alongside %s : [%s]",
+			ConstraintKeyDefault, ConstraintKeyUse, name))
+	}
+	return r, nil
+}
+
+// RefRule represent a reference to a named rule declared in $definitions
+type RefRule struct {
+	Rule
+	name   string
+	target Ruler
+}
+
+func (rule *RefRule) GetName() string {
+	return rule.name
+}
+
+// GetTarget return the rule referenced
+func (rule *RefRule) GetTarget() Ruler {
+	return rule.target
+}
+
+func (rule *RefRule) RuleType() RuleType {
+	if rule.target == nil {
+		return rule.ruleType
+	}
+	return rule.target.RuleType()
+}
+
+func (rule *RefRule) Get(key string) (Ruler, bool) {
+	return rule.target.Get(key)
+}
+
+func (rule *RefRule) MustGet(key string) Ruler {
+	return rule.target.MustGet(key)
+}
+
+func (rule *RefRule) GetRules() []Ruler {
+	return rule.target.GetRules()
+}
+
+func (rule *RefRule) restructure() error {
+	err := rule.Rule.restructure()
+	if err != nil {
+		return err
+	}
+
+	k, v, _ := GetKVNodeByKeyName(ConstraintKeyUse, rule.getContent())
+	if !validStrNode(v) {
+		return errors.New(fmt.Sprintf("value node must be string : [%s]", k.Value))
+	}
+	rule.name = v.Value
+
+	target, err := rule.scope.resolve(rule.name)
+	if err != nil {
+		return err
+	}
+	rule.target = target
+
+	//reference must finally lead to a rule which is not a reference
+	for t, ok := target.(*RefRule); ok; t, ok = t.target.(*RefRule) {
+		if t == rule {
+			return errors.New(fmt.Sprintf("circular reference of definition : [%s]", rule.name))
+		}
+	}
+
+	//type is unknown yet while target is a reference being compiled, eg,. definitions referencing each other
+	if ref := rule.getReference(); ref != nil && target.RuleType() != "" {
+		return ref.checkAs(target.RuleType(), rule.Key())
+	}
+	return nil
+}
@@ -0,0 +1,707 @@
+package invalid
+
+import (
+	"errors"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	ConstraintKeyAssert = `$assert` //expressions over fields which must be true, valid under type $obj
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenNumber
+	tokenString
+	tokenIdent
+	tokenOperator
+	tokenLParen
+	tokenRParen
+	tokenLBracket
+	tokenRBracket
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+var operators = []string{"==", "!=", "<=", ">=", "&&", "||", "<", ">", "+", "-", "*", "/", "%", "!", "."}
+
+// tokenize split expression src into tokens, keys of paths could be in any letters, eg,. 名前
+func tokenize(src string) ([]token, error) {
+	tokens := make([]token, 0)
+	for i := 0; i < len(src); {
+		c, size := utf8.DecodeRuneInString(src[i:])
+		switch {
+		case c == utf8.RuneError && size == 1:
+			return nil, errors.New(fmt.Sprintf("invalid UTF-8 encoding at %d", i))
+		case unicode.IsSpace(c):
+			i += size
+		case c == '(':
+			tokens = append(tokens, token{kind: tokenLParen, text: "(", pos: i})
+			i++
+		case c == ')':
+			tokens = append(tokens, token{kind: tokenRParen, text: ")", pos: i})
+			i++
+		case c == '[':
+			tokens = append(tokens, token{kind: tokenLBracket, text: "[", pos: i})
+			i++
+		case c == ']':
+			tokens = append(tokens, token{kind: tokenRBracket, text: "]", pos: i})
+			i++
+		case c == '"' || c == '\'':
+			end := strings.IndexRune(src[i+1:], c)
+			if end < 0 {
+				return nil, errors.New(fmt.Sprintf("unterminated string at %d", i))
+			}
+			tokens = append(tokens, token{kind: tokenString, text: src[i+1 : i+1+end], pos: i})
+			i += end + 2
+		case unicode.IsDigit(c):
+			var j int
+			if n := len(tokens); n > 0 && ((tokens[n-1].kind == tokenOperator && tokens[n-1].text == ".") ||
+				tokens[n-1].kind == tokenLBracket) {
+				//index of path, eg,. containers.0.name or containers[0]
+				j = scanRunes(src, i, unicode.IsDigit)
+			} else {
+				j = scanRunes(src, i, func(r rune) bool {
+					return unicode.IsDigit(r) || r == '.' || r == '_' || unicode.IsLetter(r)
+				})
+			}
+			tokens = append(tokens, token{kind: tokenNumber, text: src[i:j], pos: i})
+			i = j
+		case unicode.IsLetter(c) || c == '_':
+			j := scanRunes(src, i, func(r rune) bool {
+				return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
+			})
+			tokens = append(tokens, token{kind: tokenIdent, text: src[i:j], pos: i})
+			i = j
+		default:
+			matched := false
+			for _, op := range operators {
+				if strings.HasPrefix(src[i:], op) {
+					tokens = append(tokens, token{kind: tokenOperator, text: op, pos: i})
+					i += len(op)
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				return nil, errors.New(fmt.Sprintf("unexpected character [%c] at %d", c, i))
+			}
+		}
+	}
+	return append(tokens, token{kind: tokenEOF, pos: len(src)}), nil
+}
+
+// scanRunes return the end of runes in src from i which satisfy f
+func scanRunes(src string, i int, f func(rune) bool) int {
+	for i < len(src) {
+		c, size := utf8.DecodeRuneInString(src[i:])
+		if !f(c) {
+			break
+		}
+		i += size
+	}
+	return i
+}
+
+// exprNode is a node of expression tree, values of it are *big.Rat, string, bool, time.Time or nil. numbers are exact,
+// eg,. 0.1 + 0.2 == 0.3
+type exprNode interface {
+	eval(f Field) (any, error)
+}
+
+type literalNode struct {
+	value any
+}
+
+// pathNode represent path of a field relative to the mapping field, eg,. spec.replicas or containers.0.name
+type pathNode struct {
+	segments []string
+}
+
+type unaryNode struct {
+	op string
+	x  exprNode
+}
+
+type binaryNode struct {
+	op   string
+	l, r exprNode
+}
+
+// lenNode represent len(path), which is length of string or number of items or keys of field
+type lenNode struct {
+	path *pathNode
+}
+
+// hasNode represent has(path), which is whether field of path exists, eg,. an optional field
+type hasNode struct {
+	path *pathNode
+}
+
+// parser is a recursive descent parser of expression, precedence from low to high is
+// ||, &&, !, comparison, + -, * / %, unary -
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+// accept consume the next token if it's one of operators ops
+func (p *parser) accept(ops ...string) (string, bool) {
+	t := p.peek()
+	if t.kind != tokenOperator {
+		return "", false
+	}
+	for _, op := range ops {
+		if t.text == op {
+			p.next()
+			return op, true
+		}
+	}
+	return "", false
+}
+
+func (p *parser) parseOr() (exprNode, error) {
+	return p.parseBinary(p.parseAnd, "||")
+}
+
+func (p *parser) parseAnd() (exprNode, error) {
+	return p.parseBinary(p.parseNot, "&&")
+}
+
+func (p *parser) parseNot() (exprNode, error) {
+	if _, ok := p.accept("!"); ok {
+		x, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return &unaryNode{op: "!", x: x}, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *parser) parseComparison() (exprNode, error) {
+	l, err := p.parseAdd()
+	if err != nil {
+		return nil, err
+	}
+	if op, ok := p.accept("==", "!=", "<=", ">=", "<", ">"); ok {
+		r, err := p.parseAdd()
+		if err != nil {
+			return nil, err
+		}
+		return &binaryNode{op: op, l: l, r: r}, nil
+	}
+	return l, nil
+}
+
+func (p *parser) parseAdd() (exprNode, error) {
+	return p.parseBinary(p.parseMul, "+", "-")
+}
+
+func (p *parser) parseMul() (exprNode, error) {
+	return p.parseBinary(p.parseUnary, "*", "/", "%")
+}
+
+// parseBinary parse left associative binary operators ops, operands of which are parsed by operand
+func (p *parser) parseBinary(operand func() (exprNode, error), ops ...string) (exprNode, error) {
+	l, err := operand()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op, ok := p.accept(ops...)
+		if !ok {
+			return l, nil
+		}
+		r, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		l = &binaryNode{op: op, l: l, r: r}
+	}
+}
+
+func (p *parser) parseUnary() (exprNode, error) {
+	if _, ok := p.accept("-"); ok {
+		x, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &unaryNode{op: "-", x: x}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (exprNode, error) {
+	t := p.next()
+	switch t.kind {
+	case tokenNumber:
+		n, err := parseYAMLRat(yamlNodeTypeInt, strings.ReplaceAll(t.text, "_", ""))
+		if err != nil {
+			n, err = parseYAMLRat(yamlNodeTypeFloat, strings.ReplaceAll(t.text, "_", ""))
+		}
+		if err != nil {
+			return nil, errors.New(fmt.Sprintf("invalid number [%s] at %d", t.text, t.pos))
+		}
+		return &literalNode{value: n}, nil
+	case tokenString:
+		return &literalNode{value: t.text}, nil
+	case tokenLParen:
+		x, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.next().kind != tokenRParen {
+			return nil, errors.New(fmt.Sprintf("missing ) for ( at %d", t.pos))
+		}
+		return x, nil
+	case tokenIdent:
+		switch t.text {
+		case "true", "false":
+			return &literalNode{value: t.text == "true"}, nil
+		case "null":
+			return &literalNode{value: nil}, nil
+		case "len", "has":
+			if p.peek().kind == tokenLParen {
+				p.next()
+				path, err := p.parsePath(p.next())
+				if err != nil {
+					return nil, err
+				}
+				if p.next().kind != tokenRParen {
+					return nil, errors.New(fmt.Sprintf("missing ) for %s at %d", t.text, t.pos))
+				}
+				if t.text == "has" {
+					return &hasNode{path: path}, nil
+				}
+				return &lenNode{path: path}, nil
+			}
+		}
+		return p.parsePath(t)
+	case tokenLBracket:
+		return p.parsePath(t)
+	case tokenEOF:
+		return nil, errors.New("unexpected end of expression")
+	}
+	return nil, errors.New(fmt.Sprintf("unexpected [%s] at %d", t.text, t.pos))
+}
+
+// parsePath parse path starts with token t, segments of path are separated by dot, or quoted in brackets for keys
+// which are not identifiers, eg,. spec["max-replicas"] or ["x.y"].z
+func (p *parser) parsePath(t token) (*pathNode, error) {
+	path := &pathNode{}
+	switch t.kind {
+	case tokenIdent:
+		path.segments = append(path.segments, t.text)
+	case tokenLBracket:
+		s, err := p.parseBracket(t)
+		if err != nil {
+			return nil, err
+		}
+		path.segments = append(path.segments, s)
+	default:
+		return nil, errors.New(fmt.Sprintf("path expected at %d", t.pos))
+	}
+	for {
+		if b := p.peek(); b.kind == tokenLBracket {
+			s, err := p.parseBracket(p.next())
+			if err != nil {
+				return nil, err
+			}
+			path.segments = append(path.segments, s)
+			continue
+		}
+		if _, ok := p.accept("."); !ok {
+			return path, nil
+		}
+		s := p.next()
+		if s.kind != tokenIdent && !(s.kind == tokenNumber && isIndex(s.text)) {
+			return nil, errors.New(fmt.Sprintf("key or index expected at %d", s.pos))
+		}
+		path.segments = append(path.segments, s.text)
+	}
+}
+
+// parseBracket parse segment of path in brackets starts with token t, which is a quoted key or an index
+func (p *parser) parseBracket(t token) (string, error) {
+	s := p.next()
+	if s.kind != tokenString && !(s.kind == tokenNumber && isIndex(s.text)) {
+		return "", errors.New(fmt.Sprintf("quoted key or index expected at %d", s.pos))
+	}
+	if p.next().kind != tokenRBracket {
+		return "", errors.New(fmt.Sprintf("missing ] for [ at %d", t.pos))
+	}
+	return s.text, nil
+}
+
+func isIndex(s string) bool {
+	_, err := strconv.ParseUint(s, 10, 64)
+	return err == nil
+}
+
+func (n *literalNode) eval(Field) (any, error) {
+	return n.value, nil
+}
+
+// String return path in the form it's written, keys which are not identifiers are quoted in brackets
+func (n *pathNode) String() string {
+	b := &strings.Builder{}
+	for i, s := range n.segments {
+		switch {
+		case isIdent(s) || (i > 0 && isIndex(s)):
+			if i > 0 {
+				b.WriteString(".")
+			}
+			b.WriteString(s)
+		default:
+			b.WriteString(fmt.Sprintf("[%q]", s))
+		}
+	}
+	return b.String()
+}
+
+// isIdent check whether s is written as an identifier in path
+func isIdent(s string) bool {
+	for i, c := range s {
+		if !(unicode.IsLetter(c) || c == '_' || (i > 0 && unicode.IsDigit(c))) {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// field return field of path under f
+func (n *pathNode) field(f Field) (Field, error) {
+	for _, s := range n.segments {
+		child, exist := f.Get(s)
+		if !exist {
+			return nil, NewPathMissingError(n.String())
+		}
+		f = child
+	}
+	return f, nil
+}
+
+func (n *pathNode) eval(f Field) (any, error) {
+	field, err := n.field(f)
+	if err != nil {
+		return nil, err
+	}
+	switch field.Tag() {
+	case yamlNodeTypeInt, yamlNodeTypeFloat:
+		return parseYAMLRat(field.Tag(), field.Value())
+	case yamlNodeTypeBool:
+		return strconv.ParseBool(field.Value())
+	case yamlNodeTypeNull:
+		return nil, nil
+	case yamlNodeTypeStr:
+		return field.Value(), nil
+	case yamlNodeTypeTimestamp:
+		var t time.Time
+		if err := field.getValueNode().Decode(&t); err != nil {
+			return nil, errors.New(fmt.Sprintf("invalid timestamp value : [%s]", field.Value()))
+		}
+		return t, nil
+	}
+	return nil, errors.New(fmt.Sprintf("[%s] is not a scalar", n))
+}
+
+func (n *lenNode) eval(f Field) (any, error) {
+	field, err := n.path.field(f)
+	if err != nil {
+		return nil, err
+	}
+	if field.Kind() == FieldKindScalar {
+		return big.NewRat(int64(len([]rune(field.Value()))), 1), nil
+	}
+	return big.NewRat(int64(len(field.Fields())), 1), nil
+}
+
+func (n *hasNode) eval(f Field) (any, error) {
+	_, err := n.path.field(f)
+	return err == nil, nil
+}
+
+func (n *unaryNode) eval(f Field) (any, error) {
+	x, err := n.x.eval(f)
+	if err != nil {
+		return nil, err
+	}
+	switch n.op {
+	case "!":
+		b, ok := x.(bool)
+		if !ok {
+			return nil, NewOperandTypeError(n.op, x)
+		}
+		return !b, nil
+	default:
+		num, ok := x.(*big.Rat)
+		if !ok {
+			return nil, NewOperandTypeError(n.op, x)
+		}
+		return new(big.Rat).Neg(num), nil
+	}
+}
+
+func (n *binaryNode) eval(f Field) (any, error) {
+	l, err := n.l.eval(f)
+	if err != nil {
+		return nil, err
+	}
+
+	//boolean operators are short-circuit
+	if n.op == "&&" || n.op == "||" {
+		lb, ok := l.(bool)
+		if !ok {
+			return nil, NewOperandTypeError(n.op, l)
+		}
+		if (n.op == "&&" && !lb) || (n.op == "||" && lb) {
+			return lb, nil
+		}
+		r, err := n.r.eval(f)
+		if err != nil {
+			return nil, err
+		}
+		rb, ok := r.(bool)
+		if !ok {
+			return nil, NewOperandTypeError(n.op, r)
+		}
+		return rb, nil
+	}
+
+	r, err := n.r.eval(f)
+	if err != nil {
+		return nil, err
+	}
+	switch n.op {
+	case "==", "!=":
+		eq, err := equal(n.op, l, r)
+		if err != nil {
+			return nil, err
+		}
+		return eq == (n.op == "=="), nil
+	case "<", "<=", ">", ">=":
+		c, err := compare(n.op, l, r)
+		if err != nil {
+			return nil, err
+		}
+		switch n.op {
+		case "<":
+			return c < 0, nil
+		case "<=":
+			return c <= 0, nil
+		case ">":
+			return c > 0, nil
+		default:
+			return c >= 0, nil
+		}
+	}
+	return arithmetic(n.op, l, r)
+}
+
+// equal compare operands in same type, null is equal to null only
+func equal(op string, l, r any) (bool, error) {
+	if l == nil || r == nil {
+		return l == nil && r == nil, nil
+	}
+	switch lv := l.(type) {
+	case *big.Rat:
+		if rv, ok := r.(*big.Rat); ok {
+			return lv.Cmp(rv) == 0, nil
+		}
+	case string:
+		if rv, ok := r.(string); ok {
+			return lv == rv, nil
+		}
+	case bool:
+		if rv, ok := r.(bool); ok {
+			return lv == rv, nil
+		}
+	case time.Time:
+		if rv, ok := r.(time.Time); ok {
+			return lv.Equal(rv), nil
+		}
+	}
+	return false, NewOperandTypeError(op, l, r)
+}
+
+// compare order operands which are both numbers, strings or timestamps
+func compare(op string, l, r any) (int, error) {
+	switch lv := l.(type) {
+	case *big.Rat:
+		if rv, ok := r.(*big.Rat); ok {
+			return lv.Cmp(rv), nil
+		}
+	case string:
+		if rv, ok := r.(string); ok {
+			return strings.Compare(lv, rv), nil
+		}
+	case time.Time:
+		if rv, ok := r.(time.Time); ok {
+			return lv.Compare(rv), nil
+		}
+	}
+	return 0, NewOperandTypeError(op, l, r)
+}
+
+// arithmetic calculate operands which are both numbers
+func arithmetic(op string, l, r any) (any, error) {
+	lv, lok := l.(*big.Rat)
+	rv, rok := r.(*big.Rat)
+	if !lok || !rok {
+		return nil, NewOperandTypeError(op, l, r)
+	}
+
+	switch op {
+	case "+":
+		return new(big.Rat).Add(lv, rv), nil
+	case "-":
+		return new(big.Rat).Sub(lv, rv), nil
+	case "*":
+		return new(big.Rat).Mul(lv, rv), nil
+	case "/":
+		if rv.Sign() == 0 {
+			return nil, errors.New("division by zero")
+		}
+		return new(big.Rat).Quo(lv, rv), nil
+	default:
+		if !lv.IsInt() || !rv.IsInt() {
+			return nil, NewOperandTypeError(op, l, r)
+		}
+		if rv.Sign() == 0 {
+			return nil, errors.New("division by zero")
+		}
+		return new(big.Rat).SetInt(new(big.Int).Rem(lv.Num(), rv.Num())), nil
+	}
+}
+
+// typeName return name of value type in expression
+func typeName(v any) string {
+	switch v.(type) {
+	case *big.Rat:
+		return "number"
+	case string:
+		return "string"
+	case bool:
+		return "bool"
+	case time.Time:
+		return "timestamp"
+	case nil:
+		return "null"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+// expression represent a compiled expression of $assert
+type expression struct {
+	src  string
+	root exprNode
+}
+
+// compileExpr parse expression src
+func compileExpr(src string) (*expression, error) {
+	tokens, err := tokenize(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokenEOF {
+		return nil, errors.New(fmt.Sprintf("unexpected [%s] at %d", t.text, t.pos))
+	}
+	return &expression{src: src, root: root}, nil
+}
+
+// eval evaluate expression against mapping field f, errPathMissing is returned if any path does not exist
+func (e *expression) eval(f Field) (bool, error) {
+	v, err := e.root.eval(f)
+	if err != nil {
+		return false, err
+	}
+	b, ok := v.(bool)
+	if !ok {
+		return false, errors.New(fmt.Sprintf("result must be bool, got %s", typeName(v)))
+	}
+	return b, nil
+}
+
+// validateAsserts evaluate expressions of $assert against mapping field f, expression is skipped
+// while any path in it does not exist
+func (rule *ObjRule) validateAsserts(f Field, result *[]*Result) *[]*Result {
+	if f.Kind() != FieldKindMapping {
+		return result
+	}
+	for _, e := range rule.asserts {
+		ok, err := e.eval(f)
+		if err != nil || !ok {
+			result = appendResult(result, AssertMismatch, NewAssertError(e.src, err), f.getValueRange())
+		}
+	}
+	return result
+}
+
+// newAsserts compile expressions of $assert, which is an expression or a list of expressions
+func (rule *ObjRule) newAsserts() error {
+	k, v, e := GetKVNodeByKeyName(ConstraintKeyAssert, rule.getContent())
+	if !(k != nil && v != nil && e) {
+		return nil
+	}
+
+	nodes := v.Content
+	if validStrNode(v) {
+		nodes = []*yaml.Node{v}
+	} else if !validArrNode(v) {
+		return ConstraintTypeError(ConstraintKeyAssert, yamlNodeTypeSeq)
+	}
+	for _, n := range nodes {
+		if !validStrNode(n) {
+			return errors.New(fmt.Sprintf("expression must be string : [%s.%s]", rule.Key(), ConstraintKeyAssert))
+		}
+		expr, err := compileExpr(n.Value)
+		if err != nil {
+			return errors.New(fmt.Sprintf("compile expression [%s] error : [%s] %s", n.Value, rule.Key(), err.Error()))
+		}
+		rule.asserts = append(rule.asserts, expr)
+	}
+	return nil
+}
+
+func NewPathMissingError(path string) error {
+	return errors.New(fmt.Sprintf("path [%s] is not found", path))
+}
+
+func NewOperandTypeError(op string, operands ...any) error {
+	types := make([]string, 0, len(operands))
+	for _, o := range operands {
+		types = append(types, typeName(o))
+	}
+	return errors.New(fmt.Sprintf("operator [%s] is not applicable to %s", op, strings.Join(types, " and ")))
+}
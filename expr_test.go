@@ -0,0 +1,145 @@
+package invalid
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestExpr(t *testing.T) {
+	testExprEval(t)
+	testExprTypeError(t)
+	testExprSyntaxError(t)
+}
+
+const exprDocument = `
+replicas: 3
+maxReplicas: 5
+ratio: 0.5
+fee: 0.1
+tax: 0.2
+name: web
+enabled: true
+nothing: null
+startDate: "2023-01-01"
+endDate: "2023-06-30"
+released: 2023-03-01
+deadline: 2023-12-31T23:59:59Z
+limits:
+  cpu: 2
+containers:
+  - name: nginx
+  - name: sidecar
+max-replicas: 4
+名前: web
+labels:
+  app.kubernetes.io/name: web
+`
+
+func testExprEval(t *testing.T) {
+	field, err := NewYAML(strings.NewReader(exprDocument))
+	assert.Nil(t, err)
+
+	cases := []struct {
+		expr   string
+		expect bool
+	}{
+		{"replicas <= maxReplicas", true},
+		{"replicas > maxReplicas", false},
+		{"replicas + 2 == maxReplicas", true},
+		{"maxReplicas - replicas * 2 == -1", true},
+		{"(maxReplicas - replicas) * 2 == 4", true},
+		{"maxReplicas % replicas == 2", true},
+		{"maxReplicas / 2 == 2.5", true},
+		{"ratio * 2 == 1", true},
+		{"fee == 0.1 && fee <= 0.1 && fee >= 0.1", true},
+		{"fee + tax == 0.3", true},
+		{"fee * 3 == 0.3 && 0.3 / fee == 3", true},
+		{"1e3 == 1000 && 1_000 == 1000", true},
+		{"0x10 == 16", true},
+		{"name == 'web' && enabled", true},
+		{"name != \"web\" || !enabled", false},
+		{"!(replicas > 1)", false},
+		{"nothing == null", true},
+		{"name != null", true},
+		{"endDate > startDate", true},
+		{"deadline > released && released == released && !(released >= deadline)", true},
+		{"limits.cpu >= 2", true},
+		{"containers.1.name == 'sidecar'", true},
+		{"len(containers) == 2 && len(name) == 3 && len(limits) == 1", true},
+		{"enabled == false || replicas < 10", true},
+		{"[\"max-replicas\"] == 4 && replicas < ['max-replicas']", true},
+		{"名前 == 'web' && len(名前) == 3", true},
+		{"labels[\"app.kubernetes.io/name\"] == name", true},
+		{"containers[1].name == 'sidecar' && containers[0][\"name\"] == 'nginx'", true},
+		{"has(maxReplicas) && !has(minReplicas) && !has(containers.2)", true},
+		{"!has(minReplicas) || replicas >= minReplicas", true},
+	}
+	for _, c := range cases {
+		e, err := compileExpr(c.expr)
+		assert.Nil(t, err, c.expr)
+		v, err := e.eval(field)
+		assert.Nil(t, err, c.expr)
+		assert.EqualValues(t, c.expect, v, c.expr)
+	}
+
+	//path missing
+	for expr, path := range map[string]string{
+		"minReplicas <= replicas":          "minReplicas",
+		"containers.2.name == 'x'":         "containers.2.name",
+		"len(volumes) > 0":                 "volumes",
+		"max-replicas == 4":                "max",
+		"labels['app.kubernetes.io'] == 1": `labels["app.kubernetes.io"]`,
+	} {
+		e, err := compileExpr(expr)
+		assert.Nil(t, err, expr)
+		_, err = e.eval(field)
+		assert.Equal(t, NewPathMissingError(path), err, expr)
+	}
+
+	//short-circuit skips the right operand
+	e, _ := compileExpr("enabled || minReplicas > 0")
+	v, err := e.eval(field)
+	assert.Nil(t, err)
+	assert.True(t, v)
+}
+
+func testExprTypeError(t *testing.T) {
+	field, err := NewYAML(strings.NewReader(exprDocument))
+	assert.Nil(t, err)
+
+	cases := []struct {
+		expr string
+		err  string
+	}{
+		{"name > replicas", "operator [>] is not applicable to string and number"},
+		{"replicas == '3'", "operator [==] is not applicable to number and string"},
+		{"name + 1 == 2", "operator [+] is not applicable to string and number"},
+		{"replicas && enabled", "operator [&&] is not applicable to number"},
+		{"!name", "operator [!] is not applicable to string"},
+		{"-enabled == 1", "operator [-] is not applicable to bool"},
+		{"ratio % 2 == 0", "operator [%] is not applicable to number and number"},
+		{"nothing < 1", "operator [<] is not applicable to null and number"},
+		{"replicas / 0 == 1", "division by zero"},
+		{"replicas + 1", "result must be bool, got number"},
+		{"limits == 1", "[limits] is not a scalar"},
+		{"released > startDate", "operator [>] is not applicable to timestamp and string"},
+	}
+	for _, c := range cases {
+		e, err := compileExpr(c.expr)
+		assert.Nil(t, err, c.expr)
+		_, err = e.eval(field)
+		assert.EqualValues(t, errors.New(c.err), err, c.expr)
+	}
+}
+
+func testExprSyntaxError(t *testing.T) {
+	for _, expr := range []string{"", "replicas <=", "(replicas > 1", "replicas > 1)", "replicas = 1",
+		"'unterminated", "containers.-1", "len(1)", "replicas > 1 2", "a.'b'", "a[b]", "a['b'", "[]", "has('a')",
+		"a\xff"} {
+		e, err := compileExpr(expr)
+		assert.NotNil(t, err, expr)
+		assert.Nil(t, e, expr)
+	}
+}
@@ -0,0 +1,363 @@
+package invalid
+
+import (
+	"errors"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"math/big"
+	"strconv"
+)
+
+const (
+	ConstraintKeyExtends = `$extends` //definitions in type $obj which the rule inherits from, valid under type $obj
+	ConstraintKeyRemove  = `$remove`  //inherited fields to be removed, valid under $extends
+)
+
+// keys of base rule which are not inherited
+var keysNotInherited = []string{ConstraintKeyOptional, ConstraintKeyRequired, ConstraintKeyExtends}
+
+// constraints which could be overridden freely, the other constraints overridden must be checked to be tightened
+var keysOverridable = []string{ConstraintKeyOptional, ConstraintKeyRequired, ConstraintKeyDefault}
+
+// bounds of which the derived one must not be less than the base one
+var lowerBounds = []string{ConstraintKeyMinItems, ConstraintKeyMinKeys, ConstraintKeyMinContains}
+
+// bounds of which the derived one must not be greater than the base one
+var upperBounds = []string{ConstraintKeyMaxItems, ConstraintKeyMaxKeys, ConstraintKeyMaxContains,
+	ConstraintKeyMaxDecimals}
+
+// extend merge rules of definitions declared in $extends of node with node itself, and return the merged node.
+// chain is the definitions being extended, which is used to detect circular inheritance.
+func (scope *ruleScope) extend(key string, node *yaml.Node, chain []string) (*yaml.Node, error) {
+	k, v, e := GetKVNodeByKeyName(ConstraintKeyExtends, node.Content)
+	if !(k != nil && v != nil && e) {
+		return node, nil
+	}
+
+	names := []*yaml.Node{v}
+	if validArrNode(v) {
+		names = v.Content
+	}
+
+	//merge bases, a field inherited from more than one base is ambiguous
+	var base *yaml.Node
+	var from []string
+	for _, name := range names {
+		if !validStrNode(name) {
+			return nil, errors.New(fmt.Sprintf("value of %s must be string : [%s]", ConstraintKeyExtends, key))
+		}
+		b, err := scope.expandDefinition(name.Value, chain)
+		if err != nil {
+			return nil, err
+		}
+		if base == nil {
+			base = b
+			from = append(from, name.Value)
+			continue
+		}
+		for i := 0; i < len(b.Content)/2; i++ {
+			bk, bv := b.Content[i*2], b.Content[i*2+1]
+			_, exist, _ := getKVNodeInMap(bk.Value, base.Content)
+			if exist != nil {
+				if !nodeEqual(exist, bv) {
+					return nil, NewAmbiguousMergeError(key, fmt.Sprintf("field [%s] is inherited from both %v and [%s]",
+						bk.Value, from, name.Value))
+				}
+				continue
+			}
+			base.Content = append(base.Content, bk, bv)
+		}
+		from = append(from, name.Value)
+	}
+
+	return mergeRule(key, base, node)
+}
+
+// expandDefinition return node of definition in type $obj with its own bases merged
+func (scope *ruleScope) expandDefinition(name string, chain []string) (*yaml.Node, error) {
+	if contains(chain, name) {
+		return nil, errors.New(fmt.Sprintf("circular %s of definition : [%s]", ConstraintKeyExtends, name))
+	}
+	def, exist := scope.definitions[name]
+	if !exist {
+		return nil, errors.New(fmt.Sprintf("definition not found : [%s]", name))
+	}
+	_, t, _ := GetKVNodeByKeyName(ConstraintKeyType, def.valueNode.Content)
+	if t == nil || t.Value != string(RuleTypeObj) {
+		return nil, errors.New(fmt.Sprintf("definition to extend must be in type %s : [%s]", RuleTypeObj, name))
+	}
+
+	node, err := scope.extend(name, def.valueNode, append(chain, name))
+	if err != nil {
+		return nil, err
+	}
+
+	//copy content except keys not inherited, so that the definition itself is not modified
+	return &yaml.Node{
+		Kind:    yaml.MappingNode,
+		Tag:     yamlNodeTypeMap,
+		Content: getContentExcept(node, keysNotInherited...),
+	}, nil
+}
+
+// mergeRule merge rule node derived into rule node base, constraints in derived override those in base but
+// must not widen them, constraints which could not be checked must not be overridden in different values.
+// rules of fields are merged recursively and fields in $remove are removed.
+func mergeRule(key string, base, derived *yaml.Node) (*yaml.Node, error) {
+	if !validMapNode(base) || !validMapNode(derived) {
+		return nil, NewAmbiguousMergeError(key, "rule to merge must be map")
+	}
+
+	removed := make([]string, 0)
+	if k, v, e := GetKVNodeByKeyName(ConstraintKeyRemove, derived.Content); k != nil && v != nil && e {
+		if !validArrNode(v) {
+			return nil, ConstraintTypeError(ConstraintKeyRemove, yamlNodeTypeSeq)
+		}
+		for _, n := range v.Content {
+			if _, bv, _ := getKVNodeInMap(n.Value, base.Content); bv == nil {
+				return nil, NewAmbiguousMergeError(key, fmt.Sprintf("field [%s] to remove is not inherited", n.Value))
+			}
+			if _, dv, _ := getKVNodeInMap(n.Value, derived.Content); dv != nil {
+				return nil, NewAmbiguousMergeError(key, fmt.Sprintf("field [%s] is both removed and overridden", n.Value))
+			}
+			removed = append(removed, n.Value)
+		}
+	}
+
+	merged := &yaml.Node{
+		Kind:   yaml.MappingNode,
+		Tag:    yamlNodeTypeMap,
+		Line:   derived.Line,
+		Column: derived.Column,
+	}
+	for i := 0; i < len(base.Content)/2; i++ {
+		bk, bv := base.Content[i*2], base.Content[i*2+1]
+		if contains(removed, bk.Value) {
+			continue
+		}
+		dk, dv, exist := getKVNodeInMap(bk.Value, derived.Content)
+		if !exist {
+			merged.Content = append(merged.Content, bk, bv)
+			continue
+		}
+
+		if len(bk.Value) > 0 && bk.Value[0] == '$' {
+			//constraint overridden
+			if !contains(keysOverridable, bk.Value) {
+				err := checkTightening(key, bk.Value, bv, dv)
+				if err != nil {
+					return nil, err
+				}
+			}
+			merged.Content = append(merged.Content, dk, dv)
+			continue
+		}
+
+		//rule of field overridden
+		fieldKey := fmt.Sprintf("%s.%s", key, bk.Value)
+		m, err := mergeRule(fieldKey, bv, dv)
+		if err != nil {
+			return nil, err
+		}
+		//a required field could not be optional, an optional field could be required by $optional: false or
+		//$required: true
+		optional := fieldOptional(dv)
+		if optional != nil && *optional && !(validMapNode(bv) && isOptional(bv)) {
+			return nil, NewAmbiguousMergeError(fieldKey, "required field is overridden to be optional")
+		}
+		if optional != nil && !*optional {
+			m.Content = getContentExcept(m, ConstraintKeyOptional)
+		}
+		merged.Content = append(merged.Content, dk, m)
+	}
+
+	//constraints and fields only in derived
+	for i := 0; i < len(derived.Content)/2; i++ {
+		dk, dv := derived.Content[i*2], derived.Content[i*2+1]
+		if dk.Value == ConstraintKeyExtends || dk.Value == ConstraintKeyRemove {
+			continue
+		}
+		if _, bv, _ := getKVNodeInMap(dk.Value, base.Content); bv != nil {
+			continue
+		}
+		merged.Content = append(merged.Content, dk, dv)
+	}
+	return merged, nil
+}
+
+// fieldOptional return optionality declared in rule node of field, nil is returned if it's not declared
+func fieldOptional(node *yaml.Node) *bool {
+	if !validMapNode(node) {
+		return nil
+	}
+	if _, v, _ := getKVNodeInMap(ConstraintKeyOptional, node.Content); v != nil && validBoolNode(v) {
+		optional := v.Value == "true"
+		return &optional
+	}
+	if _, v, _ := getKVNodeInMap(ConstraintKeyRequired, node.Content); v != nil && validBoolNode(v) {
+		optional := v.Value != "true"
+		return &optional
+	}
+	return nil
+}
+
+// isOptional check whether rule node of field is optional
+func isOptional(node *yaml.Node) bool {
+	optional := fieldOptional(node)
+	return optional != nil && *optional
+}
+
+// checkTightening check constraint in derived is not wider than the one in base, constraints which could not be
+// checked must be equal.
+func checkTightening(key, constraint string, base, derived *yaml.Node) error {
+	switch {
+	case contains(lowerBounds, constraint), contains(upperBounds, constraint):
+		b, berr := strconv.Atoi(base.Value)
+		d, derr := strconv.Atoi(derived.Value)
+		if berr != nil || derr != nil || !validIntNode(base) || !validIntNode(derived) {
+			break
+		}
+		if contains(lowerBounds, constraint) && d < b {
+			return NewAmbiguousMergeError(key, fmt.Sprintf("%s is less than %d", constraint, b))
+		}
+		if contains(upperBounds, constraint) && d > b {
+			return NewAmbiguousMergeError(key, fmt.Sprintf("%s is greater than %d", constraint, b))
+		}
+		return nil
+	case constraint == ConstraintKeyStrict, constraint == ConstraintKeyUnique:
+		if validBoolNode(base) && validBoolNode(derived) && base.Value == "true" && derived.Value != "true" {
+			return NewAmbiguousMergeError(key, fmt.Sprintf("%s is turned off", constraint))
+		}
+		return nil
+	case constraint == ConstraintKeyMultipleOf:
+		b, berr := parseYAMLRat(base.Tag, base.Value)
+		d, derr := parseYAMLRat(derived.Tag, derived.Value)
+		if berr != nil || derr != nil || b.Sign() == 0 {
+			break
+		}
+		if !new(big.Rat).Quo(d, b).IsInt() {
+			return NewAmbiguousMergeError(key, fmt.Sprintf("%s [%s] is not a multiple of %s", constraint,
+				derived.Value, base.Value))
+		}
+		return nil
+	case constraint == ConstraintKeyRange:
+		return checkRangeTightening(key, base, derived)
+	}
+
+	switch constraint {
+	case ConstraintKeyType:
+		if !nodeEqual(base, derived) {
+			return NewAmbiguousMergeError(key, fmt.Sprintf("type [%s] is overridden by [%s]", base.Value, derived.Value))
+		}
+	case ConstraintKeyOf:
+		if !validArrNode(base) || !validArrNode(derived) {
+			return nil
+		}
+		for _, d := range derived.Content {
+			found := false
+			for _, b := range base.Content {
+				if nodeEqual(b, d) {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return NewAmbiguousMergeError(key, fmt.Sprintf("value [%s] of %s is not inherited", d.Value, ConstraintKeyOf))
+			}
+		}
+	case ConstraintKeyLength:
+		if baseMin, err := GetIntValue(ConstraintKeyMin, base.Content); err == nil {
+			if min, err := GetIntValue(ConstraintKeyMin, derived.Content); err == nil && min < baseMin {
+				return NewAmbiguousMergeError(key, fmt.Sprintf("%s of %s is less than %d", ConstraintKeyMin,
+					ConstraintKeyLength, baseMin))
+			}
+		}
+		if baseMax, err := GetIntValue(ConstraintKeyMax, base.Content); err == nil {
+			if max, err := GetIntValue(ConstraintKeyMax, derived.Content); err == nil && max > baseMax {
+				return NewAmbiguousMergeError(key, fmt.Sprintf("%s of %s is greater than %d", ConstraintKeyMax,
+					ConstraintKeyLength, baseMax))
+			}
+		}
+	default:
+		if !nodeEqual(base, derived) {
+			return NewAmbiguousMergeError(key, fmt.Sprintf("constraint %s is overridden, which could not be checked "+
+				"to be tightened", constraint))
+		}
+	}
+	return nil
+}
+
+// rangeBound represent a bound of $range in rule node
+type rangeBound struct {
+	value     *big.Float
+	exclusive bool
+}
+
+// rangeBounds return lower and upper bounds of $range node, bound is nil while it's omitted
+func rangeBounds(node *yaml.Node) (*rangeBound, *rangeBound) {
+	var lower, upper *rangeBound
+	for _, bound := range []string{ConstraintKeyMin, ConstraintKeyExMin, ConstraintKeyMax, ConstraintKeyExMax} {
+		_, v, _ := getKVNodeInMap(bound, node.Content)
+		if v == nil {
+			continue
+		}
+		n, err := parseYAMLNumber(v.Tag, v.Value)
+		if err != nil {
+			continue
+		}
+		b := &rangeBound{value: n, exclusive: bound == ConstraintKeyExMin || bound == ConstraintKeyExMax}
+		if bound == ConstraintKeyMin || bound == ConstraintKeyExMin {
+			lower = b
+		} else {
+			upper = b
+		}
+	}
+	return lower, upper
+}
+
+// checkRangeTightening check $range in derived is inside the one in base
+func checkRangeTightening(key string, base, derived *yaml.Node) error {
+	if !validMapNode(base) || !validMapNode(derived) {
+		return nil
+	}
+	baseLower, baseUpper := rangeBounds(base)
+	lower, upper := rangeBounds(derived)
+	if baseLower != nil {
+		if lower == nil {
+			return NewAmbiguousMergeError(key, fmt.Sprintf("lower bound of %s is removed", ConstraintKeyRange))
+		}
+		c := lower.value.Cmp(baseLower.value)
+		if c < 0 || (c == 0 && baseLower.exclusive && !lower.exclusive) {
+			return NewAmbiguousMergeError(key, fmt.Sprintf("lower bound of %s is less than %s", ConstraintKeyRange,
+				baseLower.value.Text('g', -1)))
+		}
+	}
+	if baseUpper != nil {
+		if upper == nil {
+			return NewAmbiguousMergeError(key, fmt.Sprintf("upper bound of %s is removed", ConstraintKeyRange))
+		}
+		c := upper.value.Cmp(baseUpper.value)
+		if c > 0 || (c == 0 && baseUpper.exclusive && !upper.exclusive) {
+			return NewAmbiguousMergeError(key, fmt.Sprintf("upper bound of %s is greater than %s", ConstraintKeyRange,
+				baseUpper.value.Text('g', -1)))
+		}
+	}
+	return nil
+}
+
+// nodeEqual check whether two nodes are equal in tag, value and content
+func nodeEqual(a, b *yaml.Node) bool {
+	if a.Kind != b.Kind || a.Tag != b.Tag || a.Value != b.Value || len(a.Content) != len(b.Content) {
+		return false
+	}
+	for i := range a.Content {
+		if !nodeEqual(a.Content[i], b.Content[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func NewAmbiguousMergeError(key, reason string) error {
+	return errors.New(fmt.Sprintf("ambiguous %s of [%s] : %s", ConstraintKeyExtends, key, reason))
+}
@@ -0,0 +1,168 @@
+package invalid
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	ConstraintKeyFormat = `$format` //name of format which value must be in, valid under type $str
+)
+
+// Format check whether value is in the format, the error returned explains why it's not
+type Format func(value string) error
+
+// built-in formats
+const (
+	FormatEmail    = "email"
+	FormatURI      = "uri"
+	FormatIPv4     = "ipv4"
+	FormatIPv6     = "ipv6"
+	FormatCIDR     = "cidr"
+	FormatHostname = "hostname"
+	FormatDateTime = "date-time"
+	FormatUUID     = "uuid"
+	FormatSemver   = "semver"
+)
+
+var (
+	formatLock sync.RWMutex
+	formats    = map[string]Format{
+		FormatEmail:    validEmail,
+		FormatURI:      validURI,
+		FormatIPv4:     validIPv4,
+		FormatIPv6:     validIPv6,
+		FormatCIDR:     validCIDR,
+		FormatHostname: validHostname,
+		FormatDateTime: validDateTime,
+		FormatUUID:     validUUID,
+		FormatSemver:   validSemver,
+
+		FormatJSONPointerRef: validJSONPointerRef,
+	}
+
+	hostnameLabelReg = regexp.MustCompile(`^[a-zA-Z0-9]([a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?$`)
+	uuidReg          = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+	semverReg        = regexp.MustCompile(`^(0|[1-9]\d*)\.(0|[1-9]\d*)\.(0|[1-9]\d*)` +
+		`(?:-((?:0|[1-9]\d*|\d*[a-zA-Z-][0-9a-zA-Z-]*)(?:\.(?:0|[1-9]\d*|\d*[a-zA-Z-][0-9a-zA-Z-]*))*))?` +
+		`(?:\+([0-9a-zA-Z-]+(?:\.[0-9a-zA-Z-]+)*))?$`)
+)
+
+// RegisterFormat register format by name, which could be used in $format of rules compiled afterwards.
+// built-in formats or formats registered already could not be replaced.
+func RegisterFormat(name string, format Format) error {
+	if name == "" || format == nil {
+		return errors.New("name and format are required to register format")
+	}
+
+	formatLock.Lock()
+	defer formatLock.Unlock()
+	if _, exist := formats[name]; exist {
+		return errors.New(fmt.Sprintf("format is registered already : [%s]", name))
+	}
+	formats[name] = format
+	return nil
+}
+
+// getFormat return format registered by name
+func getFormat(name string) (Format, bool) {
+	formatLock.RLock()
+	defer formatLock.RUnlock()
+	format, exist := formats[name]
+	return format, exist
+}
+
+func validEmail(value string) error {
+	addr, err := mail.ParseAddress(value)
+	if err != nil || addr.Address != value {
+		return errors.New("must be an email address, eg,. user@example.com")
+	}
+	return nil
+}
+
+func validURI(value string) error {
+	u, err := url.Parse(value)
+	if err != nil || u.Scheme == "" {
+		return errors.New("must be an absolute URI with scheme, eg,. https://example.com/path")
+	}
+	return nil
+}
+
+func validIPv4(value string) error {
+	ip := net.ParseIP(value)
+	if ip == nil || ip.To4() == nil || strings.Contains(value, ":") {
+		return errors.New("must be an IPv4 address, eg,. 192.168.0.1")
+	}
+	return nil
+}
+
+func validIPv6(value string) error {
+	ip := net.ParseIP(value)
+	if ip == nil || !strings.Contains(value, ":") {
+		return errors.New("must be an IPv6 address, eg,. 2001:db8::1")
+	}
+	return nil
+}
+
+func validCIDR(value string) error {
+	_, _, err := net.ParseCIDR(value)
+	if err != nil {
+		return errors.New("must be an IP address with prefix length, eg,. 10.0.0.0/8")
+	}
+	return nil
+}
+
+func validHostname(value string) error {
+	name := strings.TrimSuffix(value, ".")
+	if len(name) == 0 || len(name) > 253 {
+		return errors.New("must be a hostname in 1 to 253 characters")
+	}
+	for _, label := range strings.Split(name, ".") {
+		if !hostnameLabelReg.MatchString(label) {
+			return errors.New(fmt.Sprintf("must be a hostname, label [%s] is invalid", label))
+		}
+	}
+	return nil
+}
+
+func validDateTime(value string) error {
+	_, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return errors.New("must be an RFC 3339 date-time, eg,. 2006-01-02T15:04:05Z")
+	}
+	return nil
+}
+
+func validUUID(value string) error {
+	if !uuidReg.MatchString(value) {
+		return errors.New("must be a UUID, eg,. 123e4567-e89b-12d3-a456-426614174000")
+	}
+	return nil
+}
+
+func validSemver(value string) error {
+	if !semverReg.MatchString(value) {
+		return errors.New("must be a semantic version, eg,. 1.2.3 or 1.0.0-rc.1")
+	}
+	return nil
+}
+
+// validateFormat check value of field f is in format of the rule, reference in format json-pointer-ref is resolved
+// after that.
+func (rule *StrRule) validateFormat(ctx context.Context, f Field, result *[]*Result) *[]*Result {
+	if rule.format == nil || f.Tag() != yamlNodeTypeStr {
+		return result
+	}
+	if err := rule.format(f.Value()); err != nil {
+		return appendResult(result, FormatMismatch, NewFormatError(f.Key(), rule.formatName, err), f.getValueRange())
+	}
+	return rule.validateRef(ctx, f, result)
+}
@@ -0,0 +1,72 @@
+package invalid
+
+import (
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestFormat(t *testing.T) {
+	testBuiltinFormats(t)
+	testRegisterFormat(t)
+}
+
+func testBuiltinFormats(t *testing.T) {
+	cases := []struct {
+		format  string
+		valid   []string
+		invalid []string
+	}{
+		{FormatEmail, []string{"user@example.com", "a.b+c@sub.example.org"},
+			[]string{"user", "User <user@example.com>", "@example.com"}},
+		{FormatURI, []string{"https://example.com/path?q=1", "urn:isbn:0451450523"},
+			[]string{"/relative/path", "example.com", "http://[::1"}},
+		{FormatIPv4, []string{"192.168.0.1", "0.0.0.0"}, []string{"256.0.0.1", "::ffff:192.168.0.1", "10.0.0"}},
+		{FormatIPv6, []string{"2001:db8::1", "::1", "::ffff:192.168.0.1"}, []string{"192.168.0.1", "2001:db8:::1"}},
+		{FormatCIDR, []string{"10.0.0.0/8", "2001:db8::/32"}, []string{"10.0.0.0", "10.0.0.0/33"}},
+		{FormatHostname, []string{"example.com", "a-b.example.com.", "localhost"},
+			[]string{"-a.example.com", "a_b.example.com", "a..com", ""}},
+		{FormatDateTime, []string{"2006-01-02T15:04:05Z", "2006-01-02T15:04:05.999+08:00"},
+			[]string{"2006-01-02", "2006-01-02 15:04:05"}},
+		{FormatUUID, []string{"123e4567-e89b-12d3-a456-426614174000"},
+			[]string{"123e4567e89b12d3a456426614174000", "123e4567-e89b-12d3-a456-42661417400g"}},
+		{FormatSemver, []string{"1.2.3", "1.0.0-rc.1+build.5"}, []string{"v1.2.3", "1.2", "01.2.3"}},
+		{FormatJSONPointerRef, []string{"#/components/schemas/Pet", "#", "pet.yaml", "pet.yaml#/Pet", "#/paths/~1pet~0"},
+			[]string{"", "#components", "#/a~2b", "#/a~", "#/a%zz"}},
+	}
+
+	for _, c := range cases {
+		format, exist := getFormat(c.format)
+		assert.True(t, exist, c.format)
+		for _, v := range c.valid {
+			assert.Nil(t, format(v), "%s : %s", c.format, v)
+		}
+		for _, v := range c.invalid {
+			assert.NotNil(t, format(v), "%s : %s", c.format, v)
+		}
+	}
+}
+
+func testRegisterFormat(t *testing.T) {
+	reg := regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+	format := func(value string) error {
+		if !reg.MatchString(value) {
+			return errors.New("must be a hex color, eg,. #ff0000")
+		}
+		return nil
+	}
+	//name is unique in every run, so that formats registered by former runs of -count do not conflict
+	name := fmt.Sprintf("hex-color-%d", time.Now().UnixNano())
+	assert.Nil(t, RegisterFormat(name, format))
+	_, exist := getFormat(name)
+	assert.True(t, exist)
+
+	//formats could not be replaced
+	assert.NotNil(t, RegisterFormat(name, format))
+	assert.NotNil(t, RegisterFormat(FormatEmail, format))
+	assert.NotNil(t, RegisterFormat("", format))
+	assert.NotNil(t, RegisterFormat("nil", nil))
+}
@@ -0,0 +1,92 @@
+package invalid
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+const (
+	ConstraintKeyFunc = `$func` //name or list of names of funcs registered by RegisterFunc, valid under any type
+)
+
+// Func check field f in code, eg,. a cron expression parses or a file exists, the error returned is reported
+type Func func(f Field) error
+
+// namedFunc represent a func referenced by $func
+type namedFunc struct {
+	name string
+	fn   Func
+}
+
+var (
+	funcLock sync.RWMutex
+	funcs    = map[string]Func{}
+)
+
+// RegisterFunc register func by name, which could be used in $func of rules compiled afterwards.
+// funcs registered already could not be replaced.
+func RegisterFunc(name string, fn Func) error {
+	if name == "" || fn == nil {
+		return errors.New("name and func are required to register func")
+	}
+
+	funcLock.Lock()
+	defer funcLock.Unlock()
+	if _, exist := funcs[name]; exist {
+		return errors.New(fmt.Sprintf("func is registered already : [%s]", name))
+	}
+	funcs[name] = fn
+	return nil
+}
+
+// getFunc return func registered by name
+func getFunc(name string) (Func, bool) {
+	funcLock.RLock()
+	defer funcLock.RUnlock()
+	fn, exist := funcs[name]
+	return fn, exist
+}
+
+// newFuncs read funcs referenced by $func of rule
+func (rule *Rule) newFuncs() error {
+	key, value, _ := getKVNodeInMap(ConstraintKeyFunc, rule.getContent())
+	if key == nil || value == nil {
+		return nil
+	}
+
+	//a func name, or a list of func names
+	names := make([]string, 0)
+	switch {
+	case validStrNode(value):
+		names = append(names, value.Value)
+	case validArrNode(value):
+		for i, n := range value.Content {
+			if !validStrNode(n) {
+				return errors.New(fmt.Sprintf("func name must be string : [%s.%s.%d]", rule.Key(), ConstraintKeyFunc, i))
+			}
+			names = append(names, n.Value)
+		}
+	default:
+		return errors.New(fmt.Sprintf("value of %s must be a func name or a list of func names : [%s]",
+			ConstraintKeyFunc, rule.Key()))
+	}
+	for _, name := range names {
+		fn, exist := getFunc(name)
+		if !exist {
+			return errors.New(fmt.Sprintf("func not found : [%s]", name))
+		}
+		rule.funcs = append(rule.funcs, &namedFunc{name: name, fn: fn})
+	}
+	return nil
+}
+
+// validateFuncs call funcs of rule r with field f, and wrap errors returned into results at range of f
+func validateFuncs(r Ruler, f Field, result *[]*Result) *[]*Result {
+	for _, fn := range r.getFuncs() {
+		if err := fn.fn(f); err != nil {
+			result = appendResult(result, FuncMismatch, NewFuncError(f.Key(), fn.name, err), f.getValueRange())
+		}
+	}
+	return result
+}
@@ -0,0 +1,103 @@
+package invalid
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"io"
+	"io/fs"
+	"path"
+	"strings"
+)
+
+const (
+	ConstraintKeyImport = `$import` //rule files whose $definitions are imported, declared at top level of rule file
+)
+
+// Resolver resolves rule files imported by $import
+type Resolver interface {
+	// Resolve return the canonical name and content of rule file name imported by rule file from,
+	// from is empty while resolving the entry file.
+	Resolve(from, name string) (string, io.Reader, error)
+}
+
+// FSResolver resolves rule files in a file system, eg,. an embedded rule bundle,
+// name of imported file is relative to the file imports it
+type FSResolver struct {
+	fsys fs.FS
+}
+
+func NewFSResolver(fsys fs.FS) *FSResolver {
+	return &FSResolver{fsys: fsys}
+}
+
+func (resolver *FSResolver) Resolve(from, name string) (string, io.Reader, error) {
+	p := name
+	if from != "" && !path.IsAbs(name) {
+		p = path.Join(path.Dir(from), name)
+	}
+	p = path.Clean(strings.TrimPrefix(p, "/"))
+
+	b, err := fs.ReadFile(resolver.fsys, p)
+	if err != nil {
+		return "", nil, err
+	}
+	return p, bytes.NewReader(b), nil
+}
+
+// importFiles import definitions of rule files declared under $import of document node in rule file
+func (scope *ruleScope) importFiles(file string, document *yaml.Node) error {
+	k, v, e := GetKVNodeByKeyName(ConstraintKeyImport, document.Content)
+	if !(k != nil && v != nil && e) {
+		return nil
+	}
+	if scope.resolver == nil {
+		return errors.New(fmt.Sprintf("resolver is required for %s : [%s:%d]", ConstraintKeyImport, file, k.Line))
+	}
+
+	nodes := []*yaml.Node{v}
+	if validArrNode(v) {
+		nodes = v.Content
+	}
+	for _, node := range nodes {
+		if !validStrNode(node) {
+			return errors.New(fmt.Sprintf("value of %s must be string : [%s:%d]", ConstraintKeyImport, file, node.Line))
+		}
+
+		name, r, err := scope.resolver.Resolve(file, node.Value)
+		if err != nil {
+			return errors.New(fmt.Sprintf("import [%s] error : [%s:%d] %s", node.Value, file, node.Line, err.Error()))
+		}
+		for i := range scope.importing {
+			if scope.importing[i] == name {
+				cycle := append(append([]string{}, scope.importing[i:]...), name)
+				return NewImportCycleError(file, node.Line, cycle)
+			}
+		}
+		if scope.imported[name] {
+			continue
+		}
+
+		doc, err := readDocument(r)
+		if err != nil {
+			return err
+		}
+		scope.importing = append(scope.importing, name)
+		err = scope.importFiles(name, doc)
+		if err != nil {
+			return err
+		}
+		err = scope.addDefinitions(name, doc)
+		if err != nil {
+			return err
+		}
+		scope.importing = scope.importing[:len(scope.importing)-1]
+		scope.imported[name] = true
+	}
+	return nil
+}
+
+func NewImportCycleError(file string, line int, cycle []string) error {
+	return errors.New(fmt.Sprintf("import cycle : [%s:%d] %s", file, line, strings.Join(cycle, " -> ")))
+}
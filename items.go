@@ -0,0 +1,140 @@
+package invalid
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"strconv"
+)
+
+const (
+	ConstraintKeyItems           = `$items`            //ordered rules of items by position, valid under type $arr
+	ConstraintKeyAdditionalItems = `$additional-items` //policy of items beyond $items, valid alongside $items
+)
+
+// indexedField represent an item of list field, key of which is the path of item, eg,. backends.2
+type indexedField struct {
+	Field
+	key string
+}
+
+func (f *indexedField) Key() string {
+	return f.key
+}
+
+// newItemRule create rule of item from node, which is a type name or a rule
+func newItemRule(key string, node *yaml.Node, scope *ruleScope) (Ruler, error) {
+	keyNode := &yaml.Node{Kind: yaml.ScalarNode, Tag: yamlNodeTypeStr, Value: key, Line: node.Line, Column: node.Column}
+
+	//type name only, eg,. $int
+	if validStrNode(node) {
+		node = &yaml.Node{
+			Kind: yaml.MappingNode,
+			Tag:  yamlNodeTypeMap,
+			Content: []*yaml.Node{
+				{Kind: yaml.ScalarNode, Tag: yamlNodeTypeStr, Value: ConstraintKeyType},
+				node,
+			},
+		}
+	}
+	if !validMapNode(node) {
+		return nil, errors.New(fmt.Sprintf("rule of item must be a type name or a rule : [%s]", key))
+	}
+
+	//rule of item is $obj unless $type is declared, the same as $constraint
+	r, err := newRuler(keyNode, node, !declaresType(node), scope)
+	if err != nil {
+		return nil, err
+	}
+	err = r.restructure()
+	if err != nil {
+		return nil, err
+	}
+	err = checkDefault(r)
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// newItems read positional rules of items and policy of additional items
+func (rule *ArrRule) newItems() error {
+	k, v, e := GetKVNodeByKeyName(ConstraintKeyItems, rule.getContent())
+	if !(k != nil && v != nil && e) {
+		if k, _, e := GetKVNodeByKeyName(ConstraintKeyAdditionalItems, rule.getContent()); k != nil && e {
+			return errors.New(fmt.Sprintf("%s is valid alongside %s only : [%s]", ConstraintKeyAdditionalItems,
+				ConstraintKeyItems, rule.Key()))
+		}
+		return nil
+	}
+	if !validArrNode(v) {
+		return ConstraintTypeError(ConstraintKeyItems, yamlNodeTypeSeq)
+	}
+
+	rule.items = make([]Ruler, 0, len(v.Content))
+	for i, node := range v.Content {
+		r, err := newItemRule(fmt.Sprintf("%s.%d", rule.Key(), i), node, rule.scope)
+		if err != nil {
+			return err
+		}
+		//optional items must be at the end, so that position of item is determined
+		if r.Required() && i > 0 && !rule.items[i-1].Required() {
+			return errors.New(fmt.Sprintf("required item follows optional item : [%s.%d]", rule.Key(), i))
+		}
+		rule.items = append(rule.items, r)
+	}
+
+	//additional items are allowed by default
+	rule.additionalAllowed = true
+	k, v, e = GetKVNodeByKeyName(ConstraintKeyAdditionalItems, rule.getContent())
+	if k != nil && v != nil && e {
+		if validBoolNode(v) {
+			rule.additionalAllowed = v.Value == "true"
+			return nil
+		}
+		r, err := newItemRule(fmt.Sprintf("%s.%s", rule.Key(), ConstraintKeyAdditionalItems), v, rule.scope)
+		if err != nil {
+			return err
+		}
+		rule.additionalItems = r
+	}
+	return nil
+}
+
+// validateItems validate items of sequence field f by position
+func (rule *ArrRule) validateItems(ctx context.Context, cancel context.CancelFunc, f Field,
+	result *[]*Result) *[]*Result {
+	if f.Kind() != FieldKindSequence {
+		return result
+	}
+
+	for i := 0; i < len(f.Fields()) || i < len(rule.items); i++ {
+		if ctx.Err() == context.Canceled {
+			return result
+		}
+		path := fmt.Sprintf("%s.%d", f.Key(), i)
+		item, exist := f.Get(strconv.Itoa(i))
+		if !exist {
+			if rule.items[i].Required() {
+				result = appendResult(result, ItemMissing, NewItemMissingError(path), f.getValueRange())
+			}
+			continue
+		}
+
+		var r Ruler
+		if i < len(rule.items) {
+			r = rule.items[i]
+		} else if !rule.additionalAllowed {
+			result = appendResult(result, AdditionalItems, NewAdditionalItemError(path, len(rule.items)),
+				item.getValueRange())
+			continue
+		} else if rule.additionalItems != nil {
+			r = rule.additionalItems
+		} else {
+			continue
+		}
+		result = validateRule(ctx, cancel, r, &indexedField{Field: item, key: path}, result)
+	}
+	return result
+}
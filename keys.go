@@ -0,0 +1,156 @@
+package invalid
+
+import (
+	"errors"
+	"fmt"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	ConstraintKeyDependencies = `$dependencies` //keys required while a key is present, valid under type $obj
+	ConstraintKeyOneOfKeys    = `$one-of-keys`  //exactly one of the keys must be present, valid under type $obj
+	ConstraintKeyAnyOfKeys    = `$any-of-keys`  //at least one of the keys must be present, valid under type $obj
+	ConstraintKeyNoneOfKeys   = `$none-of-keys` //none of the keys is allowed, valid under type $obj
+)
+
+// dependency represent keys required while key is present
+type dependency struct {
+	key      string
+	requires []string
+}
+
+// keyGroups represent constraints of key presence in $obj
+type keyGroups struct {
+	dependencies []*dependency
+	oneOf        [][]string
+	anyOf        [][]string
+	noneOf       [][]string
+}
+
+// newKeyGroups create constraints of key presence from rule node
+func newKeyGroups(key string, node *yaml.Node) (*keyGroups, error) {
+	groups := &keyGroups{}
+
+	if k, v, e := GetKVNodeByKeyName(ConstraintKeyDependencies, node.Content); k != nil && v != nil && e {
+		if !validMapNode(v) {
+			return nil, ConstraintTypeError(ConstraintKeyDependencies, yamlNodeTypeMap)
+		}
+		for i := 0; i < len(v.Content)/2; i++ {
+			dk, dv := v.Content[i*2], v.Content[i*2+1]
+			requires, err := keyNames(fmt.Sprintf("%s.%s", key, dk.Value), dv)
+			if err != nil {
+				return nil, err
+			}
+			groups.dependencies = append(groups.dependencies, &dependency{key: dk.Value, requires: requires})
+		}
+	}
+
+	var err error
+	for _, c := range []struct {
+		name   string
+		groups *[][]string
+	}{
+		{ConstraintKeyOneOfKeys, &groups.oneOf},
+		{ConstraintKeyAnyOfKeys, &groups.anyOf},
+		{ConstraintKeyNoneOfKeys, &groups.noneOf},
+	} {
+		k, v, e := GetKVNodeByKeyName(c.name, node.Content)
+		if !(k != nil && v != nil && e) {
+			continue
+		}
+		*c.groups, err = keyNameGroups(fmt.Sprintf("%s.%s", key, c.name), v)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return groups, nil
+}
+
+// keyNames return key names in list node
+func keyNames(key string, node *yaml.Node) ([]string, error) {
+	if !validArrNode(node) {
+		return nil, ConstraintTypeError(key, yamlNodeTypeSeq)
+	}
+	names := make([]string, 0, len(node.Content))
+	for i, n := range node.Content {
+		if n.Kind != yaml.ScalarNode {
+			return nil, errors.New(fmt.Sprintf("key name must be scalar : [%s.%d]", key, i))
+		}
+		names = append(names, n.Value)
+	}
+	return names, nil
+}
+
+// keyNameGroups return groups of key names in list node, a list of key names is a single group
+// and a list of lists is a group for each of them
+func keyNameGroups(key string, node *yaml.Node) ([][]string, error) {
+	if !validArrNode(node) {
+		return nil, ConstraintTypeError(key, yamlNodeTypeSeq)
+	}
+	if len(node.Content) == 0 || !validArrNode(node.Content[0]) {
+		names, err := keyNames(key, node)
+		if err != nil {
+			return nil, err
+		}
+		return [][]string{names}, nil
+	}
+
+	groups := make([][]string, 0, len(node.Content))
+	for i, n := range node.Content {
+		names, err := keyNames(fmt.Sprintf("%s.%d", key, i), n)
+		if err != nil {
+			return nil, err
+		}
+		groups = append(groups, names)
+	}
+	return groups, nil
+}
+
+// present return keys exist under mapping field f
+func present(f Field, keys []string) []string {
+	result := make([]string, 0)
+	for _, k := range keys {
+		if _, exist := f.Get(k); exist {
+			result = append(result, k)
+		}
+	}
+	return result
+}
+
+// validateKeyGroups check presence of keys under mapping field f, results are reported at range of f
+func (rule *ObjRule) validateKeyGroups(f Field, result *[]*Result) *[]*Result {
+	if rule.keyGroups == nil {
+		return result
+	}
+
+	for _, d := range rule.keyGroups.dependencies {
+		if _, exist := f.Get(d.key); !exist {
+			continue
+		}
+		missing := make([]string, 0)
+		for _, k := range d.requires {
+			if _, exist := f.Get(k); !exist {
+				missing = append(missing, k)
+			}
+		}
+		if len(missing) > 0 {
+			result = appendResult(result, DependencyMissing, NewDependencyError(d.key, missing), f.getValueRange())
+		}
+	}
+	for _, keys := range rule.keyGroups.oneOf {
+		if p := present(f, keys); len(p) != 1 {
+			result = appendResult(result, OneOfKeysMismatch, NewOneOfKeysError(keys, p), f.getValueRange())
+		}
+	}
+	for _, keys := range rule.keyGroups.anyOf {
+		if p := present(f, keys); len(p) == 0 {
+			result = appendResult(result, AnyOfKeysMismatch, NewAnyOfKeysError(keys), f.getValueRange())
+		}
+	}
+	for _, keys := range rule.keyGroups.noneOf {
+		if p := present(f, keys); len(p) > 0 {
+			result = appendResult(result, NoneOfKeysMismatch, NewNoneOfKeysError(keys, p), f.getValueRange())
+		}
+	}
+	return result
+}
@@ -0,0 +1,101 @@
+package invalid
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+const (
+	ConstraintKeyMultipleOf  = `$multiple-of`  //number must be a multiple of the value, valid under type $int and $float
+	ConstraintKeyMaxDecimals = `$max-decimals` //maximum number of decimal places, valid under type $float
+)
+
+// decimal represent a positive number of $multiple-of in exact value, and the text it's written in
+type decimal struct {
+	value *big.Rat
+	text  string
+}
+
+// parseYAMLRat parse int or float in exact value, eg,. 0.1 is 1/10 instead of the nearest float64.
+// infinity and NaN are not supported.
+func parseYAMLRat(tag, value string) (*big.Rat, error) {
+	switch tag {
+	case yamlNodeTypeInt:
+		i, ok := new(big.Int).SetString(value, 0)
+		if !ok {
+			return nil, errors.New(fmt.Sprintf("invalid int value : [%s]", value))
+		}
+		return new(big.Rat).SetInt(i), nil
+	case yamlNodeTypeFloat:
+		r, ok := new(big.Rat).SetString(strings.TrimPrefix(value, "+"))
+		if !ok {
+			return nil, errors.New(fmt.Sprintf("invalid float value : [%s]", value))
+		}
+		return r, nil
+	}
+	return nil, errors.New(fmt.Sprintf("value is not a number : [%s]", value))
+}
+
+// newDecimals read $multiple-of and $max-decimals of number rule
+func (rule *NumberRule) newDecimals() error {
+	k, v, e := GetKVNodeByKeyName(ConstraintKeyMultipleOf, rule.getContent())
+	if k != nil && v != nil && e {
+		//multiple of float could be written in int
+		if !(validIntNode(v) || (rule.ruleType == RuleTypeFloat && validFloatNode(v))) {
+			return ConstraintTypeError(fmt.Sprintf("%s.%s", rule.Key(), ConstraintKeyMultipleOf), string(rule.ruleType))
+		}
+		n, err := parseYAMLRat(v.Tag, v.Value)
+		if err != nil {
+			return err
+		}
+		if n.Sign() <= 0 {
+			return errors.New(fmt.Sprintf("value of %s must be positive : [%s]", ConstraintKeyMultipleOf, rule.Key()))
+		}
+		rule.multipleOf = &decimal{value: n, text: v.Value}
+	}
+
+	k, v, e = GetKVNodeByKeyName(ConstraintKeyMaxDecimals, rule.getContent())
+	if k != nil && v != nil && e {
+		if rule.ruleType != RuleTypeFloat {
+			return errors.New(fmt.Sprintf("%s is valid under type %s only : [%s]", ConstraintKeyMaxDecimals,
+				RuleTypeFloat, rule.Key()))
+		}
+		if !validIntNode(v) {
+			return errors.New(fmt.Sprintf("value of %s must be int : [%s]", ConstraintKeyMaxDecimals, rule.Key()))
+		}
+		n, err := strconv.Atoi(v.Value)
+		if err != nil || n < 0 {
+			return errors.New(fmt.Sprintf("value of %s must be a non-negative int : [%s]", ConstraintKeyMaxDecimals,
+				rule.Key()))
+		}
+		rule.maxDecimals = &n
+	}
+	return nil
+}
+
+// validateDecimals check value of field f is a multiple of $multiple-of, and in decimal places of $max-decimals
+func (rule *NumberRule) validateDecimals(f Field, result *[]*Result) *[]*Result {
+	if rule.multipleOf == nil && rule.maxDecimals == nil {
+		return result
+	}
+	n, err := parseYAMLRat(f.Tag(), f.Value())
+
+	if rule.multipleOf != nil && (err != nil || !new(big.Rat).Quo(n, rule.multipleOf.value).IsInt()) {
+		result = appendResult(result, MultipleOfMismatch, NewMultipleOfError(f.Key(), rule.multipleOf.text),
+			f.getValueRange())
+	}
+	if rule.maxDecimals != nil && (err != nil || !withinDecimals(n, *rule.maxDecimals)) {
+		result = appendResult(result, DecimalsMismatch, NewMaxDecimalsError(f.Key(), *rule.maxDecimals),
+			f.getValueRange())
+	}
+	return result
+}
+
+// withinDecimals check whether n has at most places decimal places, trailing zeros are not counted
+func withinDecimals(n *big.Rat, places int) bool {
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(places)), nil)
+	return new(big.Rat).Mul(n, new(big.Rat).SetInt(scale)).IsInt()
+}
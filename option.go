@@ -0,0 +1,53 @@
+package invalid
+
+import (
+	"context"
+	"gopkg.in/yaml.v3"
+	"io/fs"
+	"os"
+)
+
+// ValidateOption represent an option of validation
+type ValidateOption func(o *validateOptions)
+
+type validateOptions struct {
+	strict bool                  //report undeclared keys of all $obj
+	baseFS fs.FS                 //directory which file refs in format json-pointer-ref are relative to
+	files  map[string]*yaml.Node //files loaded by refs
+}
+
+type optionsKey struct{}
+
+// WithStrict make validation strict for all rules in type $obj, keys which are not declared are reported,
+// it's the same with constraint $strict declared in every $obj.
+func WithStrict() ValidateOption {
+	return func(o *validateOptions) {
+		o.strict = true
+	}
+}
+
+// WithBaseDir resolve file refs in format json-pointer-ref relative to dir, eg,. directory of the file validated.
+// file refs are not resolved without it, and files outside of dir are not read.
+func WithBaseDir(dir string) ValidateOption {
+	return func(o *validateOptions) {
+		o.baseFS = os.DirFS(dir)
+	}
+}
+
+// withOptions return a context carries options of validation
+func withOptions(ctx context.Context, opts []ValidateOption) context.Context {
+	o := &validateOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return context.WithValue(ctx, optionsKey{}, o)
+}
+
+// getOptions return options of validation carried by ctx
+func getOptions(ctx context.Context) *validateOptions {
+	o, ok := ctx.Value(optionsKey{}).(*validateOptions)
+	if !ok {
+		return &validateOptions{}
+	}
+	return o
+}
@@ -0,0 +1,160 @@
+package invalid
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"io/fs"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// FormatJSONPointerRef is a reference in form of [file]#/json/pointer, eg,. $ref in OpenAPI. besides the form,
+// pointer is resolved against the document being validated, or the file relative to directory set by WithBaseDir.
+const FormatJSONPointerRef = "json-pointer-ref"
+
+func validJSONPointerRef(value string) error {
+	_, pointer, err := splitRef(value)
+	if err != nil {
+		return err
+	}
+	for i := 0; i < len(pointer); i++ {
+		if pointer[i] == '~' && (i+1 == len(pointer) || (pointer[i+1] != '0' && pointer[i+1] != '1')) {
+			return errors.New("must be a JSON pointer reference, ~ must be escaped as ~0")
+		}
+	}
+	return nil
+}
+
+// splitRef split reference into file and JSON pointer
+func splitRef(value string) (string, string, error) {
+	file, fragment, found := strings.Cut(value, "#")
+	if !found && file == "" {
+		return "", "", errors.New("must be a JSON pointer reference, eg,. #/components/schemas/Pet")
+	}
+	pointer, err := url.PathUnescape(fragment)
+	if err != nil || (pointer != "" && !strings.HasPrefix(pointer, "/")) {
+		return "", "", errors.New("must be a JSON pointer reference, pointer must start with /")
+	}
+	return file, pointer, nil
+}
+
+// pointerTokens return unescaped reference tokens of JSON pointer
+func pointerTokens(pointer string) []string {
+	if pointer == "" {
+		return nil
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, t := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(t, "~1", "/"), "~0", "~")
+	}
+	return tokens
+}
+
+// resolveRef check reference exists, refs in the document are resolved against root field carried by ctx,
+// refs of files are resolved only while base directory is set, and refs of URL are not resolved.
+func resolveRef(ctx context.Context, value string) error {
+	file, pointer, err := splitRef(value)
+	if err != nil {
+		return err
+	}
+	tokens := pointerTokens(pointer)
+
+	if file == "" {
+		root, ok := getRoot(ctx)
+		if !ok {
+			return nil
+		}
+		f := root
+		for i, t := range tokens {
+			child, exist := f.Get(t)
+			if !exist {
+				return NewPointerKeyError(t, "#"+pointerPrefix(tokens[:i]))
+			}
+			f = child
+		}
+		return nil
+	}
+
+	o := getOptions(ctx)
+	if o.baseFS == nil {
+		return nil
+	}
+	if u, err := url.Parse(file); err == nil && u.Scheme != "" {
+		return nil
+	}
+	node, err := o.loadFile(file)
+	if err != nil {
+		return err
+	}
+	for i, t := range tokens {
+		var child *yaml.Node
+		switch node.Kind {
+		case yaml.MappingNode:
+			_, child, _ = getKVNodeInMap(t, node.Content)
+		case yaml.SequenceNode:
+			if n, err := strconv.Atoi(t); err == nil && n >= 0 && n < len(node.Content) {
+				child = node.Content[n]
+			}
+		}
+		if child == nil {
+			return NewPointerKeyError(t, file+"#"+pointerPrefix(tokens[:i]))
+		}
+		node = child
+	}
+	return nil
+}
+
+// pointerPrefix return JSON pointer of tokens
+func pointerPrefix(tokens []string) string {
+	escaped := make([]string, 0, len(tokens))
+	for _, t := range tokens {
+		escaped = append(escaped, "/"+strings.ReplaceAll(strings.ReplaceAll(t, "~", "~0"), "/", "~1"))
+	}
+	return strings.Join(escaped, "")
+}
+
+// loadFile return document node of file relative to base directory, files are loaded once in a validation.
+// files are read in base directory only, so refs of absolute path or leaving it by .. are rejected.
+func (o *validateOptions) loadFile(file string) (*yaml.Node, error) {
+	name := path.Clean(file)
+	if !fs.ValidPath(name) {
+		return nil, errors.New(fmt.Sprintf("file [%s] is outside of base directory", file))
+	}
+	if node, exist := o.files[name]; exist {
+		return node, nil
+	}
+
+	by, err := fs.ReadFile(o.baseFS, name)
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("file [%s] is not found", file))
+	}
+	doc := &yaml.Node{}
+	err = yaml.Unmarshal(by, doc)
+	if err != nil || len(doc.Content) < 1 {
+		return nil, errors.New(fmt.Sprintf("file [%s] is not a YAML or JSON document", file))
+	}
+	if o.files == nil {
+		o.files = map[string]*yaml.Node{}
+	}
+	o.files[name] = doc.Content[0]
+	return doc.Content[0], nil
+}
+
+// validateRef resolve reference in value of field f, which is in format json-pointer-ref
+func (rule *StrRule) validateRef(ctx context.Context, f Field, result *[]*Result) *[]*Result {
+	if rule.formatName != FormatJSONPointerRef || f.Tag() != yamlNodeTypeStr {
+		return result
+	}
+	if err := resolveRef(ctx, f.Value()); err != nil {
+		result = appendResult(result, RefUnresolved, NewRefUnresolvedError(f.Key(), f.Value(), err), f.getValueRange())
+	}
+	return result
+}
+
+func NewPointerKeyError(key, pointer string) error {
+	return errors.New(fmt.Sprintf("key [%s] is not found at [%s]", key, pointer))
+}
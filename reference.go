@@ -0,0 +1,184 @@
+package invalid
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	ConstraintKeyRefersTo = `$refers-to` //path in the same document where value of field must exist, valid under any type
+	ConstraintKeyPath     = `$path`      //dotted path from root of document, * matches every child, valid under $refers-to
+	ConstraintKeyAs       = `$as`        //what value of field is looked up as at $path, valid under $refers-to
+)
+
+// lookups of $as
+const (
+	ReferAsValue = "value" //scalar value must be equal to a scalar at path, the default one
+	ReferAsKey   = "key"   //scalar value or every key of mapping must be a key of a mapping at path
+	ReferAsEntry = "entry" //every key and value of mapping must be in a mapping at path, eg,. a subset of labels
+)
+
+// reference represent a $refers-to of rule
+type reference struct {
+	path []string
+	as   string
+}
+
+type rootKey struct{}
+
+// withRoot return a context carries root field of document, which paths of $refers-to start from
+func withRoot(ctx context.Context, f Field) context.Context {
+	return context.WithValue(ctx, rootKey{}, f)
+}
+
+// getRoot return root field of document carried by ctx
+func getRoot(ctx context.Context) (Field, bool) {
+	f, ok := ctx.Value(rootKey{}).(Field)
+	return f, ok && f != nil
+}
+
+// newReference read $refers-to of rule, a string is a shorthand of $path looked up as value
+func (rule *Rule) newReference() error {
+	k, v, _ := getKVNodeInMap(ConstraintKeyRefersTo, rule.getContent())
+	if k == nil || v == nil {
+		return nil
+	}
+
+	ref := &reference{as: ReferAsValue}
+	path := v
+	if validMapNode(v) {
+		_, path, _ = getKVNodeInMap(ConstraintKeyPath, v.Content)
+		if path == nil {
+			return errors.New(fmt.Sprintf("%s is required in %s : [%s]", ConstraintKeyPath, ConstraintKeyRefersTo,
+				rule.Key()))
+		}
+		if _, as, _ := getKVNodeInMap(ConstraintKeyAs, v.Content); as != nil {
+			if !validStrNode(as) || !contains([]string{ReferAsValue, ReferAsKey, ReferAsEntry}, as.Value) {
+				return errors.New(fmt.Sprintf("value of %s must be one of [%s %s %s] : [%s]", ConstraintKeyAs,
+					ReferAsValue, ReferAsKey, ReferAsEntry, rule.Key()))
+			}
+			ref.as = as.Value
+		}
+	}
+	if !validStrNode(path) || path.Value == "" {
+		return ConstraintTypeError(ConstraintKeyPath, yamlNodeTypeStr)
+	}
+	ref.path = strings.Split(path.Value, ".")
+	rule.reference = ref
+
+	//type of rule in $use is unknown until definition is resolved, which is checked by RefRule
+	if _, use, _ := getKVNodeInMap(ConstraintKeyUse, rule.getContent()); use != nil {
+		return nil
+	}
+	return ref.checkAs(rule.RuleType(), rule.Key())
+}
+
+// checkAs check lookup of reference is valid under rule type t
+func (ref *reference) checkAs(t RuleType, key string) error {
+	if ref.as == ReferAsEntry && t != RuleTypeObj {
+		return errors.New(fmt.Sprintf("%s of %s is valid under type %s only : [%s]", ReferAsEntry, ConstraintKeyAs,
+			RuleTypeObj, key))
+	}
+	return nil
+}
+
+func (ref *reference) String() string {
+	return strings.Join(ref.path, ".")
+}
+
+// resolve return fields at path of reference under root, and fields of the deepest level reached by path,
+// which are where targets were looked up.
+func (ref *reference) resolve(root Field) ([]Field, []Field) {
+	current, reached := []Field{root}, []Field{root}
+	for _, seg := range ref.path {
+		next := make([]Field, 0)
+		for _, c := range current {
+			if seg == "*" {
+				next = append(next, c.Fields()...)
+			} else if child, exist := c.Get(seg); exist {
+				next = append(next, child)
+			}
+		}
+		if len(next) == 0 {
+			return nil, reached
+		}
+		current, reached = next, next
+	}
+	return current, reached
+}
+
+// hasValue check whether any scalar target is equal to value
+func hasValue(targets []Field, value string) bool {
+	for _, t := range targets {
+		if t.Kind() == FieldKindScalar && t.Value() == value {
+			return true
+		}
+	}
+	return false
+}
+
+// hasKey check whether any mapping target has key, and its value equal to value if value is not nil
+func hasKey(targets []Field, key string, value *string) bool {
+	for _, t := range targets {
+		if t.Kind() != FieldKindMapping {
+			continue
+		}
+		child, exist := t.Get(key)
+		if exist && (value == nil || (child.Kind() == FieldKindScalar && child.Value() == *value)) {
+			return true
+		}
+	}
+	return false
+}
+
+// validateReference check value of field f exists at path of $refers-to in the document being validated,
+// range where targets were looked up is reported as related range.
+func validateReference(ctx context.Context, r Ruler, f Field, result *[]*Result) *[]*Result {
+	ref := r.getReference()
+	root, ok := getRoot(ctx)
+	if ref == nil || !ok {
+		return result
+	}
+
+	targets, reached := ref.resolve(root)
+	var lookup *Range
+	for _, t := range reached {
+		if lookup == nil {
+			lookup = t.getValueRange()
+		} else if rng := t.getValueRange(); rng != nil {
+			lookup = lookup.expend(rng)
+		}
+	}
+
+	report := func(value string, rng *Range) {
+		result = appendResult(result, RefersToMismatch, NewRefersToError(f.Key(), value, ref.as, ref.String()), rng)
+		(*result)[len(*result)-1].RelatedRange = lookup
+	}
+
+	switch {
+	case f.Kind() == FieldKindScalar && ref.as == ReferAsValue:
+		if !hasValue(targets, f.Value()) {
+			report(f.Value(), f.getValueRange())
+		}
+	case f.Kind() == FieldKindScalar && ref.as == ReferAsKey:
+		if !hasKey(targets, f.Value(), nil) {
+			report(f.Value(), f.getValueRange())
+		}
+	case f.Kind() == FieldKindMapping && ref.as == ReferAsKey:
+		for _, child := range f.Fields() {
+			if !hasKey(targets, child.Key(), nil) {
+				report(child.Key(), child.KeyRange())
+			}
+		}
+	case f.Kind() == FieldKindMapping && ref.as == ReferAsEntry:
+		for _, child := range f.Fields() {
+			value := child.Value()
+			if child.Kind() != FieldKindScalar || !hasKey(targets, child.Key(), &value) {
+				report(fmt.Sprintf("%s: %s", child.Key(), value), child.KeyRange())
+			}
+		}
+	}
+	return result
+}
@@ -11,6 +11,57 @@ const (
 	StrLengthMismatch            = "strLengthMismatch"
 	RegxMismatch                 = "regxMismatch"
 	OfMismatch                   = "ofMismatch"
+	SeqMismatch                  = "seqMismatch"
+	RangeMismatch                = "rangeMismatch"
+	KeyOfMismatch                = "keyOfMismatch"
+	KeyNameMismatch              = "keyNameMismatch"
+	UnknownKey                   = "unknownKey"
+	KeyForbidden                 = "keyForbidden"
+)
+
+// results of key presence, range of them covers the parent mapping
+const (
+	DependencyMissing  ResultType = "dependencyMissing"
+	OneOfKeysMismatch  ResultType = "oneOfKeysMismatch"
+	AnyOfKeysMismatch  ResultType = "anyOfKeysMismatch"
+	NoneOfKeysMismatch ResultType = "noneOfKeysMismatch"
+)
+
+// results of number of children in collection, range of them covers the whole collection
+const (
+	ItemCountMismatch ResultType = "itemCountMismatch"
+	KeyCountMismatch  ResultType = "keyCountMismatch"
+)
+
+const (
+	DuplicateItem    ResultType = "duplicateItem"
+	ItemMissing      ResultType = "itemMissing"
+	AdditionalItems  ResultType = "additionalItems"
+	ContainsMismatch ResultType = "containsMismatch"
+)
+
+const (
+	FormatMismatch ResultType = "formatMismatch"
+	FuncMismatch   ResultType = "funcMismatch"
+	AssertMismatch ResultType = "assertMismatch"
+)
+
+const (
+	RefersToMismatch ResultType = "refersToMismatch"
+	RefUnresolved    ResultType = "refUnresolved"
+)
+
+const (
+	MultipleOfMismatch ResultType = "multipleOfMismatch"
+	DecimalsMismatch   ResultType = "decimalsMismatch"
+)
+
+const (
+	PrefixMismatch    ResultType = "prefixMismatch"
+	SuffixMismatch    ResultType = "suffixMismatch"
+	SubstringMismatch ResultType = "substringMismatch"
+	NotRegxMismatch   ResultType = "notRegxMismatch"
+	CaseMismatch      ResultType = "caseMismatch"
 )
 
 type ResultType string
@@ -19,6 +70,8 @@ type Result struct {
 	Type  ResultType
 	Error error
 	Range *Range
+	//RelatedRange is range of another field related to the result, eg,. the first occurrence of a duplicate item
+	RelatedRange *Range
 }
 
 func NewKeyMissingError(key string) error {
@@ -41,10 +94,125 @@ func NewRegxError(key, regx string) error {
 	return errors.New(fmt.Sprintf("value for [%s] must match regexp : %s", key, regx))
 }
 
+func NewSeqMismatchError(key string, alternatives []string) error {
+	return errors.New(fmt.Sprintf("value of [%s] matches none of %v", key, alternatives))
+}
+
+func NewRangeError(key, r string) error {
+	return errors.New(fmt.Sprintf("value of [%s] must be in range %s", key, r))
+}
+
 func NewKeyNameError(key, regx string) error {
 	return errors.New(fmt.Sprintf("key name for [%s] must match regexp ： %s", key, regx))
 }
 
+func NewKeyOfError(key string, of []string) error {
+	return errors.New(fmt.Sprintf("key [%s] must be one of %v", key, of))
+}
+
+func NewUnknownKeyError(key string) error {
+	return errors.New(fmt.Sprintf("key [%s] is not declared", key))
+}
+
+func NewKeyForbiddenError(key string) error {
+	return errors.New(fmt.Sprintf("key [%s] is not allowed here", key))
+}
+
+func NewDependencyError(key string, missing []string) error {
+	return errors.New(fmt.Sprintf("keys %v are required alongside [%s]", missing, key))
+}
+
+func NewOneOfKeysError(keys, present []string) error {
+	return errors.New(fmt.Sprintf("exactly one of keys %v is expected, found %v", keys, present))
+}
+
+func NewAnyOfKeysError(keys []string) error {
+	return errors.New(fmt.Sprintf("at least one of keys %v is expected", keys))
+}
+
+func NewNoneOfKeysError(keys, present []string) error {
+	return errors.New(fmt.Sprintf("none of keys %v is allowed, found %v", keys, present))
+}
+
+func NewItemCountError(key string, count int, bound string) error {
+	return errors.New(fmt.Sprintf("number of items in [%s] must be %s, got %d", key, bound, count))
+}
+
+func NewKeyCountError(key string, count int, bound string) error {
+	return errors.New(fmt.Sprintf("number of keys in [%s] must be %s, got %d", key, bound, count))
+}
+
+func NewDuplicateItemError(key, first string, by []string) error {
+	if by == nil {
+		return errors.New(fmt.Sprintf("item [%s] duplicates [%s]", key, first))
+	}
+	return errors.New(fmt.Sprintf("item [%s] duplicates [%s] by %v", key, first, by))
+}
+
+func NewItemMissingError(key string) error {
+	return errors.New(fmt.Sprintf("item [%s] is expected here", key))
+}
+
+func NewAdditionalItemError(key string, count int) error {
+	return errors.New(fmt.Sprintf("item [%s] is not allowed, at most %d items are expected", key, count))
+}
+
+func NewContainsError(key string, count int, bound string) error {
+	return errors.New(fmt.Sprintf("number of items in [%s] matching %s must be %s, got %d", key,
+		ConstraintKeyContains, bound, count))
+}
+
+func NewFormatError(key, format string, err error) error {
+	return errors.New(fmt.Sprintf("value of [%s] is not in format [%s] : %s", key, format, err.Error()))
+}
+
+func NewFuncError(key, name string, err error) error {
+	return errors.New(fmt.Sprintf("value of [%s] is rejected by func [%s] : %s", key, name, err.Error()))
+}
+
+func NewAssertError(expr string, err error) error {
+	if err != nil {
+		return errors.New(fmt.Sprintf("assertion [%s] failed : %s", expr, err.Error()))
+	}
+	return errors.New(fmt.Sprintf("assertion [%s] failed", expr))
+}
+
+func NewRefersToError(key, value, as, path string) error {
+	return errors.New(fmt.Sprintf("[%s] of [%s] is not found as %s at [%s]", value, key, as, path))
+}
+
+func NewRefUnresolvedError(key, ref string, err error) error {
+	return errors.New(fmt.Sprintf("ref [%s] of [%s] is not resolved : %s", ref, key, err.Error()))
+}
+
+func NewMultipleOfError(key, multiple string) error {
+	return errors.New(fmt.Sprintf("value of [%s] must be a multiple of %s", key, multiple))
+}
+
+func NewMaxDecimalsError(key string, places int) error {
+	return errors.New(fmt.Sprintf("value of [%s] must have at most %d decimal places", key, places))
+}
+
+func NewPrefixError(key, prefix string) error {
+	return errors.New(fmt.Sprintf("value of [%s] must start with [%s]", key, prefix))
+}
+
+func NewSuffixError(key, suffix string) error {
+	return errors.New(fmt.Sprintf("value of [%s] must end with [%s]", key, suffix))
+}
+
+func NewSubstringError(key, substring string) error {
+	return errors.New(fmt.Sprintf("value of [%s] must contain [%s]", key, substring))
+}
+
+func NewNotRegxError(key, regx string) error {
+	return errors.New(fmt.Sprintf("value of [%s] must not match regexp : %s", key, regx))
+}
+
+func NewCaseError(key, letterCase string) error {
+	return errors.New(fmt.Sprintf("value of [%s] must be in %s case", key, letterCase))
+}
+
 func NewResult(t ResultType, err error, r *Range) Result {
 	return Result{
 		Type:  t,
@@ -7,7 +7,11 @@ import (
 	"github.com/elliotchance/pie/v2"
 	"gopkg.in/yaml.v3"
 	"io"
+	"io/fs"
+	"math/big"
 	"regexp"
+	"strings"
+	"time"
 )
 
 type RuleType string
@@ -25,6 +29,8 @@ const (
 	yamlNodeTypeMap   string = "!!map"
 	yamlNodeTypeNull  string = "!!null"
 
+	yamlNodeTypeTimestamp string = "!!timestamp"
+
 	//single types
 	RuleTypeNil   RuleType = "$null"
 	RuleTypeAny   RuleType = "$any"
@@ -37,11 +43,18 @@ const (
 	RuleTypeObj RuleType = "$obj" //an object value contains sub-ruleMap inside, mostly it's a map
 	RuleTypeSeq RuleType = "$seq" //a list with value in any type
 	RuleTypeArr RuleType = "$arr"
+
+	//union of types, declared as a list of types in $type
+	RuleTypeUnion RuleType = "$union"
 )
 
 // yaml scalar nodes, include bool, integer, float, string and null, but null was not included here.
 var scalarTypes = []string{string(RuleTypeBool), string(RuleTypeInt),
-	string(RuleTypeFloat), string(RuleTypeStr)}
+	string(RuleTypeFloat), string(RuleTypeStr), string(RuleTypeAny)}
+
+// tags of yaml scalar nodes which are accepted by type $any
+var scalarTags = []string{yamlNodeTypeBool, yamlNodeTypeInt, yamlNodeTypeFloat, yamlNodeTypeStr, yamlNodeTypeNull,
+	yamlNodeTypeTimestamp}
 
 const (
 	ConstraintKeyType       = `$type`       //type definition
@@ -56,7 +69,26 @@ const (
 	ConstraintKeyOf         = "$of"         //constraint `of` is a approach to define enumeration value of a scalar field.it's valid under any scalar field.
 )
 
-var specKeyInObj = []string{ConstraintKeyType, ConstraintKeyRequired, ConstraintKeyOptional, ConstraintKeyKReg}
+// constraints of keys
+const (
+	ConstraintKeyKeyOf         = `$key-of`         //enumeration of key names, valid under type $obj
+	ConstraintKeyStrict        = `$strict`         //keys not declared are reported while it's true, valid under type $obj
+	ConstraintKeyPatternFields = `$pattern-fields` //rules of keys matching regexp, valid under type $obj
+)
+
+// constraints of number
+const (
+	ConstraintKeyRange = `$range`         //range of number, valid in type $int and $float
+	ConstraintKeyExMin = `$exclusive-min` //exclusive minimum of number, valid under constraint $range
+	ConstraintKeyExMax = `$exclusive-max` //exclusive maximum of number, valid under constraint $range
+)
+
+var specKeyInObj = []string{ConstraintKeyType, ConstraintKeyRequired, ConstraintKeyOptional, ConstraintKeyKReg,
+	ConstraintKeyKeyOf, ConstraintKeyStrict, ConstraintKeyPatternFields, ConstraintKeyDefinitions,
+	ConstraintKeyImport, ConstraintKeyExtends, ConstraintKeyRemove, ConstraintKeyIf, ConstraintKeyThen, ConstraintKeyElse,
+	ConstraintKeyConditions, ConstraintKeyDependencies, ConstraintKeyOneOfKeys, ConstraintKeyAnyOfKeys,
+	ConstraintKeyNoneOfKeys, ConstraintKeyMinKeys, ConstraintKeyMaxKeys, ConstraintKeyDefault,
+	ConstraintKeyFunc, ConstraintKeyAssert, ConstraintKeyRefersTo}
 
 func init() {
 	yamlTypeMapping = map[string]RuleType{
@@ -87,27 +119,51 @@ type Ruler interface {
 	Key() string
 	GetRules() []Ruler
 	Required() bool
-	Validate(f Field) []*Result
+	getDefault() *yaml.Node
+	getFuncs() []*namedFunc
+	getReference() *reference
+	Validate(f Field, opts ...ValidateOption) []*Result
 }
 
 func NewRule(r io.Reader) (Ruler, error) {
-	byte, err := io.ReadAll(r)
+	return newRule("", r, newRuleScope(nil))
+}
+
+// NewRuleWithResolver create rule from rule file name, rule files imported by $import are resolved by resolver
+func NewRuleWithResolver(name string, resolver Resolver) (Ruler, error) {
+	name, r, err := resolver.Resolve("", name)
 	if err != nil {
 		return nil, err
 	}
+	return newRule(name, r, newRuleScope(resolver))
+}
 
-	node := &yaml.Node{}
-	err = yaml.Unmarshal(byte, node)
+// NewRuleFS create rule from rule file name in fsys, rule files imported by $import are resolved in fsys as well
+func NewRuleFS(fsys fs.FS, name string) (Ruler, error) {
+	return NewRuleWithResolver(name, NewFSResolver(fsys))
+}
+
+func newRule(name string, r io.Reader, scope *ruleScope) (Ruler, error) {
+	node, err := readDocument(r)
 	if err != nil {
 		return nil, err
 	}
 
-	if len(node.Content) < 1 {
-		return nil, errors.New("document must have at least one field")
+	scope.importing = append(scope.importing, name)
+	err = scope.importFiles(name, node)
+	if err != nil {
+		return nil, err
+	}
+	err = scope.addDefinitions(name, node)
+	if err != nil {
+		return nil, err
+	}
+	err = scope.compile()
+	if err != nil {
+		return nil, err
 	}
-	node = node.Content[0]
 
-	ruler, err := newRuler(nil, node, true)
+	ruler, err := newRuler(nil, node, true, scope)
 	if err != nil {
 		return nil, err
 	}
@@ -119,6 +175,25 @@ func NewRule(r io.Reader) (Ruler, error) {
 	return ruler, nil
 }
 
+// readDocument read the first document node of rule file
+func readDocument(r io.Reader) (*yaml.Node, error) {
+	byte, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	node := &yaml.Node{}
+	err = yaml.Unmarshal(byte, node)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(node.Content) < 1 {
+		return nil, errors.New("document must have at least one field")
+	}
+	return node.Content[0], nil
+}
+
 type Rule struct {
 	required  bool //field's required
 	keyNode   *yaml.Node
@@ -126,12 +201,16 @@ type Rule struct {
 	ruleType  RuleType //type field in validation file
 	ruleMap   map[string]Ruler
 	ruleList  []Ruler
+	scope     *ruleScope //scope of rule file, shared by all rules in the file
+	defNode   *yaml.Node //default value of optional field
+	funcs     []*namedFunc
+	reference *reference //$refers-to
 }
 
-func (rule *Rule) Validate(f Field) []*Result {
+func (rule *Rule) Validate(f Field, opts ...ValidateOption) []*Result {
 
-	ctx, cancel := context.WithCancel(context.Background())
-	result := doValidate(ctx, cancel, rule, f, nil)
+	ctx, cancel := context.WithCancel(withRoot(withOptions(context.Background(), opts), f))
+	result := doValidate(ctx, cancel, rule, f, nil, nil)
 	if *result == nil {
 		x := make([]*Result, 0)
 		return x
@@ -140,7 +219,9 @@ func (rule *Rule) Validate(f Field) []*Result {
 
 }
 
-func doValidate(ctx context.Context, cancel context.CancelFunc, rule Ruler, field Field, result *[]*Result) *[]*Result {
+// doValidate validate children of field against rules of rule, $optional of rules could be overridden by overrides
+func doValidate(ctx context.Context, cancel context.CancelFunc, rule Ruler, field Field,
+	overrides map[string]*optionalOverride, result *[]*Result) *[]*Result {
 	if result == nil {
 		result = new([]*Result)
 	}
@@ -156,178 +237,227 @@ func doValidate(ctx context.Context, cancel context.CancelFunc, rule Ruler, fiel
 
 		r := rule.GetRules()[i]
 		f, e := field.Get(r.Key())
+		required := r.Required()
+		o, overridden := overrides[r.Key()]
+		if overridden {
+			required = !o.optional
+		}
 		//check key required missing
-		if !e && r.Required() {
-			err := NewResult(KeyMissing, NewKeyMissingError(r.Key()), field.Fields()[i].getValueRange())
+		if !e && required {
+			keyErr := NewKeyMissingError(r.Key())
+			if overridden {
+				keyErr = NewConditionalError(keyErr, o.condition)
+			}
+			err := NewResult(KeyMissing, keyErr, field.getValueRange())
 			x := *result
 			v := append(x, &err)
 			cancel()
 			return &v
-		} else if !e && f == nil && !r.Required() {
+		} else if !e && f == nil && !required {
 			continue
 		}
-		switch v := r.(type) {
-		case *ObjRule:
-			result = doValidate(ctx, cancel, r, f, result)
-		case *ArrRule:
-			switch v.constraint.(type) {
-			//scalar constraint
-			case string:
-				for i := 0; i < len(f.Fields()); i++ {
-					if string(f.Fields()[i].ValueType()) != v.constraint {
-						e := NewResult(TypeMismatch, NewTypeMismatchError(fmt.Sprintf("%s.%s", f.Key(),
-							f.Fields()[i].Key()), v.constraint.(string)), f.getValueRange())
-						x := *result
-						y := append(x, &e)
-						result = &y
-					}
-				}
-			//for ruler object
-			case Ruler:
-				for i := 0; i < len(f.Fields()); i++ {
-					if ctx.Err() == context.Canceled {
-						return result
-					}
-					result = doValidate(ctx, cancel, v.constraint.(Ruler), f.Fields()[i], result)
-				}
-			}
+		result = validateRule(ctx, cancel, r, f, result)
+	}
 
-		case *StrRule:
-			if f.Tag() != yamlNodeTypeStr {
-				e := NewResult(TypeMismatch, NewTypeMismatchError(f.Key(), string(RuleTypeStr)), f.getValueRange())
-				x := *result
-				y := append(x, &e)
-				result = &y
-			}
+	return result
+}
 
-			//check min or max
-			if v.max != 0 || v.min != 0 {
-				if v.min != 0 && len(f.Value()) < int(v.min) {
-					e := NewResult(StrLengthMismatch, NewStrLengthError1(r.Key(), int(v.min)), f.getValueRange())
-					x := *result
-					y := append(x, &e)
-					result = &y
-				} else if v.max != 0 && len(f.Value()) > int(v.max) {
-					e := NewResult(StrLengthMismatch, NewStrLengthError2(r.Key(), int(v.max)), f.getValueRange())
-					x := *result
-					y := append(x, &e)
-					result = &y
+// validateRule validate field f against a single rule r
+func validateRule(ctx context.Context, cancel context.CancelFunc, r Ruler, f Field, result *[]*Result) *[]*Result {
+	if result == nil {
+		result = new([]*Result)
+	}
+	count := len(*result)
+
+	switch v := r.(type) {
+	case *ObjRule:
+		branches := v.activeBranches(f)
+		result = v.validateKeys(ctx, f, result)
+		result = v.validateKeyGroups(f, result)
+		result = v.validateKeyCount(f, result)
+		result = doValidate(ctx, cancel, r, f, optionalOverrides(branches), result)
+		result = v.validatePatternFields(ctx, cancel, f, result)
+		result = validateBranches(ctx, cancel, f, branches, result)
+
+		//assertions are evaluated only if fields pass the other constraints
+		if len(*result) == count {
+			result = v.validateAsserts(f, result)
+		}
+	case *ArrRule:
+		if f.Tag() != yamlNodeTypeSeq {
+			result = appendResult(result, TypeMismatch, NewTypeMismatchError(f.Key(), string(RuleTypeArr)), f.getValueRange())
+			break
+		}
+		result = v.validateItemCount(f, result)
+		result = v.validateUnique(f, result)
+		result = v.validateContains(ctx, f, result)
+		if v.items != nil {
+			result = v.validateItems(ctx, cancel, f, result)
+		}
+		switch v.constraint.(type) {
+		//scalar constraint
+		case string:
+			for i := 0; i < len(f.Fields()); i++ {
+				if !matchScalarType(v.constraint.(string), f.Fields()[i]) {
+					result = appendResult(result, TypeMismatch, NewTypeMismatchError(fmt.Sprintf("%s.%s", f.Key(),
+						f.Fields()[i].Key()), v.constraint.(string)), f.getValueRange())
 				}
 			}
-
-			//check regexp
-			if v.GetReg() != nil {
-				m := v.regexp.Match([]byte(f.Value()))
-				if !m {
-					e := NewResult(RegxMismatch, NewRegxError(r.Key(), v.GetReg().String()), f.getValueRange())
-					x := *result
-					y := append(x, &e)
-					result = &y
+		//for ruler object
+		case Ruler:
+			for i := 0; i < len(f.Fields()); i++ {
+				if ctx.Err() == context.Canceled {
+					return result
 				}
+				result = validateRule(ctx, cancel, v.constraint.(Ruler), f.Fields()[i], result)
 			}
+		}
 
-			//check constraint of
-			if v.of != nil && len(v.of) > 0 {
-				of := pie.Map(v.of, func(t any) string {
-					return fmt.Sprintf("%v", t)
-				})
-				if !pie.Contains(of, f.Value()) {
-					e := NewResult(OfMismatch, OfContainError(f.Key(), v.of), f.getValueRange())
-					x := *result
-					y := append(x, &e)
-					result = &y
-				}
-			}
+	case *RefRule:
+		result = validateRule(ctx, cancel, v.target, f, result)
 
-		case *IntRule:
-			if f.Tag() != yamlNodeTypeInt {
-				e := NewResult(TypeMismatch, NewTypeMismatchError(f.Key(), string(RuleTypeInt)), f.getValueRange())
-				x := *result
-				y := append(x, &e)
-				result = &y
+	case *UnionRule:
+		//field is valid if any branch passes
+		matched := false
+		for i := range v.branches {
+			if matchRule(ctx, v.branches[i], f) {
+				matched = true
+				break
 			}
+		}
+		if !matched {
+			result = appendResult(result, TypeMismatch, NewTypeMismatchError(f.Key(), v.types()), f.getValueRange())
+		}
 
-			//check constraint of
-			if v.of != nil && len(v.of) > 0 {
-				of := pie.Map(v.of, func(t any) string {
-					return fmt.Sprintf("%v", t)
-				})
-				if !pie.Contains(of, f.Value()) {
-					e := NewResult(OfMismatch, OfContainError(f.Key(), v.of), f.getValueRange())
-					x := *result
-					y := append(x, &e)
-					result = &y
-				}
+	case *SeqRule:
+		if f.Tag() != yamlNodeTypeSeq {
+			result = appendResult(result, TypeMismatch, NewTypeMismatchError(f.Key(), string(RuleTypeSeq)), f.getValueRange())
+			break
+		}
+		if len(v.alternatives) == 0 {
+			break
+		}
+		for i := 0; i < len(f.Fields()); i++ {
+			item := f.Fields()[i]
+			if _, m := v.match(ctx, item); m {
+				continue
 			}
-
-		case *FloatRule:
-			if f.Tag() != yamlNodeTypeFloat {
-				e := NewResult(TypeMismatch, NewTypeMismatchError(f.Key(), string(RuleTypeFloat)), f.getValueRange())
-				x := *result
-				y := append(x, &e)
-				result = &y
+			//results of the first alternative in type of element are reported, which element is meant to be
+			if c, m := v.matchType(item); m {
+				itemCtx, itemCancel := context.WithCancel(ctx)
+				result = validateRule(itemCtx, itemCancel, c.(Ruler), item, result)
+				itemCancel()
+				continue
 			}
+			tried := pie.Map(v.alternatives, describeConstraint)
+			result = appendResult(result, SeqMismatch, NewSeqMismatchError(fmt.Sprintf("%s.%s", f.Key(),
+				item.Key()), tried), item.getValueRange())
+		}
 
-			//check constraint of
-			if v.of != nil && len(v.of) > 0 {
-				of := pie.Map(v.of, func(t any) string {
-					return fmt.Sprintf("%v", t)
-				})
-				if !pie.Contains(of, f.Value()) {
-					e := NewResult(OfMismatch, OfContainError(f.Key(), v.of), f.getValueRange())
-					x := *result
-					y := append(x, &e)
-					result = &y
-				}
-			}
+	case *StrRule:
+		if f.Tag() != yamlNodeTypeStr {
+			result = appendResult(result, TypeMismatch, NewTypeMismatchError(f.Key(), string(RuleTypeStr)), f.getValueRange())
+		}
 
-		case *BoolRule:
-			if f.Tag() != yamlNodeTypeBool {
-				e := NewResult(TypeMismatch, NewTypeMismatchError(f.Key(), string(RuleTypeBool)), f.getValueRange())
-				x := *result
-				y := append(x, &e)
-				result = &y
+		//check min or max
+		if v.max != 0 || v.min != 0 {
+			if v.min != 0 && len(f.Value()) < int(v.min) {
+				result = appendResult(result, StrLengthMismatch, NewStrLengthError1(f.Key(), int(v.min)), f.getValueRange())
+			} else if v.max != 0 && len(f.Value()) > int(v.max) {
+				result = appendResult(result, StrLengthMismatch, NewStrLengthError2(f.Key(), int(v.max)), f.getValueRange())
 			}
+		}
 
-			//check constraint of
-			if v.of != nil && len(v.of) > 0 {
-				of := pie.Map(v.of, func(t any) string {
-					return fmt.Sprintf("%v", t)
-				})
-				if !pie.Contains(of, f.Value()) {
-					e := NewResult(OfMismatch, OfContainError(f.Key(), v.of), f.getValueRange())
-					x := *result
-					y := append(x, &e)
-					result = &y
-				}
+		//check regexp
+		if v.GetReg() != nil {
+			m := v.regexp.Match([]byte(f.Value()))
+			if !m {
+				result = appendResult(result, RegxMismatch, NewRegxError(f.Key(), v.GetReg().String()), f.getValueRange())
 			}
+		}
 
-		case *NullFieldRule:
-			if f.Tag() != yamlNodeTypeNull {
-				e := NewResult(TypeMismatch, NewTypeMismatchError(f.Key(), string(RuleTypeNil)), f.getValueRange())
-				x := *result
-				y := append(x, &e)
-				result = &y
-			}
+		//check prefix, suffix, substring, negative regexp and letter case
+		result = v.validateAffixes(f, result)
 
-			//check constraint of
-			if v.of != nil && len(v.of) > 0 {
-				of := pie.Map(v.of, func(t any) string {
-					return fmt.Sprintf("%v", t)
-				})
-				if !pie.Contains(of, f.Value()) {
-					e := NewResult(OfMismatch, OfContainError(f.Key(), v.of), f.getValueRange())
-					x := *result
-					y := append(x, &e)
-					result = &y
-				}
-			}
+		//check format
+		result = v.validateFormat(ctx, f, result)
+
+		//check constraint of
+		result = v.validateOf(f, result)
+
+	case *IntRule:
+		if f.Tag() != yamlNodeTypeInt {
+			result = appendResult(result, TypeMismatch, NewTypeMismatchError(f.Key(), string(RuleTypeInt)), f.getValueRange())
+		} else {
+			//check range
+			result = v.validateRange(f, result)
+			result = v.validateDecimals(f, result)
 		}
+
+		//check constraint of
+		result = v.validateOf(f, result)
+
+	case *FloatRule:
+		if f.Tag() != yamlNodeTypeFloat {
+			result = appendResult(result, TypeMismatch, NewTypeMismatchError(f.Key(), string(RuleTypeFloat)), f.getValueRange())
+		} else {
+			//check range
+			result = v.validateRange(f, result)
+			result = v.validateDecimals(f, result)
+		}
+
+		//check constraint of
+		result = v.validateOf(f, result)
+
+	case *BoolRule:
+		if f.Tag() != yamlNodeTypeBool {
+			result = appendResult(result, TypeMismatch, NewTypeMismatchError(f.Key(), string(RuleTypeBool)), f.getValueRange())
+		}
+
+		//check constraint of
+		result = v.validateOf(f, result)
+
+	case *NullFieldRule:
+		if f.Tag() != yamlNodeTypeNull {
+			result = appendResult(result, TypeMismatch, NewTypeMismatchError(f.Key(), string(RuleTypeNil)), f.getValueRange())
+		}
+
+		//check constraint of
+		result = v.validateOf(f, result)
+
+	case *AnyRule:
+		if !contains(scalarTags, f.Tag()) {
+			result = appendResult(result, TypeMismatch, NewTypeMismatchError(f.Key(), string(RuleTypeAny)), f.getValueRange())
+		}
+
+		//check constraint of
+		result = v.validateOf(f, result)
 	}
 
+	//funcs and references are checked only if field passes the other constraints
+	if len(*result) == count && ctx.Err() != context.Canceled {
+		result = validateFuncs(r, f, result)
+		result = validateReference(ctx, r, f, result)
+	}
 	return result
 }
 
+// appendResult append a new result to result list and return the new list
+func appendResult(result *[]*Result, t ResultType, err error, r *Range) *[]*Result {
+	e := NewResult(t, err, r)
+	x := *result
+	y := append(x, &e)
+	return &y
+}
+
+// matchScalarType check whether the value type of field f match the scalar type t
+func matchScalarType(t string, f Field) bool {
+	if RuleType(t) == RuleTypeAny {
+		return contains(scalarTags, f.Tag())
+	}
+	return string(f.ValueType()) == t
+}
+
 func (rule *Rule) GetRuleMap() map[string]Ruler {
 	return rule.ruleMap
 }
@@ -340,6 +470,18 @@ func (rule *Rule) Required() bool {
 	return rule.required
 }
 
+func (rule *Rule) getDefault() *yaml.Node {
+	return rule.defNode
+}
+
+func (rule *Rule) getFuncs() []*namedFunc {
+	return rule.funcs
+}
+
+func (rule *Rule) getReference() *reference {
+	return rule.reference
+}
+
 func (rule *Rule) Get(key string) (Ruler, bool) {
 	if rule.ruleMap == nil {
 		return nil, false
@@ -408,20 +550,131 @@ func (rule *Rule) restructure() error {
 		rule.required = true
 	}
 
-	return nil
+	//handle default
+	key, value, _ = getKVNodeInMap(ConstraintKeyDefault, rule.getContent())
+	if key != nil && value != nil {
+		if rule.required {
+			return errors.New(fmt.Sprintf("%s is valid for optional field only : [%s]", ConstraintKeyDefault,
+				rule.Key()))
+		}
+		rule.defNode = value
+	}
+
+	//handle funcs
+	err := rule.newFuncs()
+	if err != nil {
+		return err
+	}
+
+	//handle reference
+	return rule.newReference()
 }
 
 type ObjRule struct {
 	Rule
-	keyRegExp *regexp.Regexp
+	keyRegExp  *regexp.Regexp
+	keyOf      []string //enumeration of key names
+	strict     bool     //report keys which are not declared
+	patterns   []*patternField
+	conditions []*condition
+	keyGroups  *keyGroups   //constraints of key presence
+	keys       *cardinality //bounds of number of keys
+	asserts    []*expression
+}
+
+// patternField represent a rule applied to every key matching the regexp
+type patternField struct {
+	regexp *regexp.Regexp
+	rule   Ruler
 }
 
 func (rule *ObjRule) GetKeyReg() *regexp.Regexp {
 	return rule.keyRegExp
 }
 
+func (rule *ObjRule) GetKeyOf() []string {
+	return rule.keyOf
+}
+
+// GetPatternRule return rule of the first pattern matching key
+func (rule *ObjRule) GetPatternRule(key string) (Ruler, bool) {
+	for _, p := range rule.patterns {
+		if p.regexp.MatchString(key) {
+			return p.rule, true
+		}
+	}
+	return nil, false
+}
+
+func (rule *ObjRule) Validate(f Field, opts ...ValidateOption) []*Result {
+	ctx, cancel := context.WithCancel(withRoot(withOptions(context.Background(), opts), f))
+	result := validateRule(ctx, cancel, rule, f, nil)
+	if *result == nil {
+		x := make([]*Result, 0)
+		return x
+	}
+	return *result
+}
+
+// validateKeys check names of keys under mapping field f
+func (rule *ObjRule) validateKeys(ctx context.Context, f Field, result *[]*Result) *[]*Result {
+	strict := rule.strict || getOptions(ctx).strict
+	for _, child := range f.Fields() {
+		_, known := rule.Get(child.Key())
+		if _, matched := rule.GetPatternRule(child.Key()); matched || rule.declaredInConditions(child.Key()) {
+			known = true
+		}
+		reported := false
+
+		if rule.keyOf != nil {
+			if contains(rule.keyOf, child.Key()) {
+				known = true
+			} else {
+				result = appendResult(result, KeyOfMismatch, NewKeyOfError(child.Key(), rule.keyOf), child.KeyRange())
+				reported = true
+			}
+		}
+		if rule.keyRegExp != nil {
+			if rule.keyRegExp.MatchString(child.Key()) {
+				known = true
+			} else {
+				result = appendResult(result, KeyNameMismatch, NewKeyNameError(child.Key(), rule.keyRegExp.String()),
+					child.KeyRange())
+				reported = true
+			}
+		}
+
+		//key which is not accounted for by any rule
+		if strict && !known && !reported {
+			result = appendResult(result, UnknownKey, NewUnknownKeyError(child.Key()), child.KeyRange())
+		}
+	}
+	return result
+}
+
+// validatePatternFields validate every key under mapping field f by rule of the pattern it matched
+func (rule *ObjRule) validatePatternFields(ctx context.Context, cancel context.CancelFunc, f Field,
+	result *[]*Result) *[]*Result {
+	for _, child := range f.Fields() {
+		if ctx.Err() == context.Canceled {
+			return result
+		}
+		if r, matched := rule.GetPatternRule(child.Key()); matched {
+			result = validateRule(ctx, cancel, r, child, result)
+		}
+	}
+	return result
+}
+
 func (rule *ObjRule) restructure() error {
-	err := rule.Rule.restructure()
+	//merge rules inherited
+	node, err := rule.scope.extend(rule.Key(), rule.valueNode, nil)
+	if err != nil {
+		return err
+	}
+	rule.valueNode = node
+
+	err = rule.Rule.restructure()
 	if err != nil {
 		return err
 	}
@@ -431,7 +684,7 @@ func (rule *ObjRule) restructure() error {
 	for i := 0; i < len(nodes)/2; i++ {
 		k := nodes[i*2]
 		v := nodes[i*2+1]
-		r, e := newRuler(k, v, false)
+		r, e := newRuler(k, v, false, rule.scope)
 		if e != nil {
 			return e
 		}
@@ -439,6 +692,10 @@ func (rule *ObjRule) restructure() error {
 		if e != nil {
 			return e
 		}
+		e = checkDefault(r)
+		if e != nil {
+			return e
+		}
 		rule.addRule(k.Value, r)
 	}
 
@@ -455,6 +712,97 @@ func (rule *ObjRule) restructure() error {
 		rule.keyRegExp = reg
 	}
 
+	//handle pattern fields
+	k, v, e = GetKVNodeByKeyName(ConstraintKeyPatternFields, rule.getContent())
+	if k != nil && v != nil && e {
+		if !validMapNode(v) {
+			return ConstraintTypeError(ConstraintKeyPatternFields, yamlNodeTypeMap)
+		}
+		for i := 0; i < len(v.Content)/2; i++ {
+			pk := v.Content[i*2]
+			pv := v.Content[i*2+1]
+			reg, err := regexp.Compile(pk.Value)
+			if err != nil {
+				return errors.New(fmt.Sprintf("regexp compile error : [%s]", pk.Value))
+			}
+			r, err := newRuler(pk, pv, false, rule.scope)
+			if err != nil {
+				return err
+			}
+			err = r.restructure()
+			if err != nil {
+				return err
+			}
+			//keys of pattern are unknown, so there's no key to fill in by default
+			if r.getDefault() != nil {
+				return errors.New(fmt.Sprintf("%s is not applicable to %s : [%s]", ConstraintKeyDefault,
+					ConstraintKeyPatternFields, pk.Value))
+			}
+			rule.patterns = append(rule.patterns, &patternField{regexp: reg, rule: r})
+		}
+	}
+
+	//handle strict
+	k, v, e = GetKVNodeByKeyName(ConstraintKeyStrict, rule.getContent())
+	if k != nil && v != nil && e {
+		if !validBoolNode(v) {
+			return errors.New(fmt.Sprintf("value node must be boolean : [%s]", k.Value))
+		}
+		rule.strict = v.Value == "true"
+	}
+
+	//handle key of
+	k, v, e = GetKVNodeByKeyName(ConstraintKeyKeyOf, rule.getContent())
+	if k != nil && v != nil && e {
+		if !validArrNode(v) {
+			return ConstraintTypeError(rule.Key(), yamlNodeTypeSeq)
+		}
+		rule.keyOf = make([]string, 0, len(v.Content))
+		for i := range v.Content {
+			if v.Content[i].Kind != yaml.ScalarNode {
+				return errors.New(fmt.Sprintf("value of %s must be scalar : [%s.%d]", ConstraintKeyKeyOf, rule.Key(), i))
+			}
+			rule.keyOf = append(rule.keyOf, v.Content[i].Value)
+		}
+	}
+
+	//handle assertions
+	err = rule.newAsserts()
+	if err != nil {
+		return err
+	}
+
+	//handle number of keys
+	rule.keys, err = newCardinality(rule.Key(), rule.valueNode, ConstraintKeyMinKeys, ConstraintKeyMaxKeys)
+	if err != nil {
+		return err
+	}
+
+	//handle key dependencies and exclusivity
+	rule.keyGroups, err = newKeyGroups(rule.Key(), rule.valueNode)
+	if err != nil {
+		return err
+	}
+
+	//handle conditions
+	rule.conditions, err = newConditions(rule.Key(), rule.valueNode, rule.scope)
+	if err != nil {
+		return err
+	}
+	for _, c := range rule.conditions {
+		for _, b := range []*conditionBranch{c.then, c.els} {
+			if b == nil {
+				continue
+			}
+			for k := range b.optional {
+				if _, exist := rule.Get(k); !exist && !pie.Any(b.rules, func(r Ruler) bool { return r.Key() == k }) {
+					return errors.New(fmt.Sprintf("rule to override %s not found : [%s.%s]", ConstraintKeyOptional,
+						rule.Key(), k))
+				}
+			}
+		}
+	}
+
 	return nil
 }
 
@@ -464,6 +812,17 @@ type Constraint interface{}
 type ArrRule struct {
 	Rule
 	constraint Constraint
+	itemCount  *cardinality //bounds of number of items
+	unique     bool         //items must be unique
+	uniqueBy   []string     //keys of which items must be unique
+
+	//rules of items by position, items beyond them are validated by additionalItems while additionalAllowed is true
+	items             []Ruler
+	additionalItems   Ruler
+	additionalAllowed bool
+
+	contains      Ruler        //rule which some of the items must match
+	containsCount *cardinality //bounds of number of items matching contains
 }
 
 func (rule *ArrRule) GetConstraint() interface{} {
@@ -476,40 +835,266 @@ func (rule *ArrRule) restructure() error {
 		return err
 	}
 
-	//check constraint
+	//check items by position
+	err = rule.newItems()
+	if err != nil {
+		return err
+	}
+
+	//check rule of contains
+	err = rule.newContains()
+	if err != nil {
+		return err
+	}
+
+	//check constraint, which is not required while items are declared by position or contains is declared
 	key, value, exist := GetKVNodeByKeyName(ConstraintKeyConstraint, rule.getContent())
 	if key != nil && value != nil && exist {
-		//constraint is node
-		if validMapNode(value) {
-			ruler, err := newRuler(key, value, true)
-			if err != nil {
-				return err
+		if rule.items != nil {
+			return errors.New(fmt.Sprintf("%s and %s are exclusive : [%s]", ConstraintKeyConstraint,
+				ConstraintKeyItems, rule.Key()))
+		}
+		rule.constraint, err = newConstraint(key, value, rule.scope)
+		if err != nil {
+			return err
+		}
+	} else if rule.items == nil && rule.contains == nil {
+		return errors.New(fmt.Sprintf("constraint for key [%s] missing", rule.Key()))
+	}
+
+	//check number of items
+	rule.itemCount, err = newCardinality(rule.Key(), rule.valueNode, ConstraintKeyMinItems, ConstraintKeyMaxItems)
+	if err != nil {
+		return err
+	}
+
+	//check uniqueness of items
+	return rule.newUnique()
+}
+
+// newConstraint create constraint from value node, which is either a string value of scalar type or an obj rule
+func newConstraint(key, value *yaml.Node, scope *ruleScope) (Constraint, error) {
+	//constraint is node, which is $obj unless $type is declared
+	if validMapNode(value) {
+		ruler, err := newRuler(key, value, !declaresType(value), scope)
+		if err != nil {
+			return nil, err
+		}
+
+		err = ruler.restructure()
+		if err != nil {
+			return nil, err
+		}
+		return ruler, nil
+
+	} else if validStrNode(value) {
+		if !contains(scalarTypes, value.Value) {
+			return nil, errors.New(fmt.Sprintf("constraint should be one of %v", value.Value))
+		}
+		return value.Value, nil
+	}
+	return nil, errors.New("constraint format should be a string value of scalar type or obj")
+}
+
+// declaresType check whether rule node declares its type by $type
+func declaresType(node *yaml.Node) bool {
+	k, v, e := GetKVNodeByKeyName(ConstraintKeyType, node.Content)
+	return k != nil && v != nil && e
+}
+
+// describeConstraint return the type name of constraint, name of definition for $use, and required keys for $obj,
+// eg,. $obj{name,port}
+func describeConstraint(c Constraint) string {
+	switch v := c.(type) {
+	case string:
+		return v
+	case *RefRule:
+		return v.GetName()
+	case Ruler:
+		if v.RuleType() != RuleTypeObj {
+			return string(v.RuleType())
+		}
+		keys := make([]string, 0)
+		for _, r := range v.GetRules() {
+			if r.Required() {
+				keys = append(keys, r.Key())
 			}
+		}
+		return fmt.Sprintf("%s{%s}", RuleTypeObj, strings.Join(keys, ","))
+	}
+	return ""
+}
 
-			err = ruler.restructure()
-			if err != nil {
-				return err
+// matchConstraintType check whether field f is in type of constraint c, regardless of the other constraints
+func matchConstraintType(c Constraint, f Field) bool {
+	switch v := c.(type) {
+	case string:
+		return matchScalarType(v, f)
+	case *RefRule:
+		return matchConstraintType(v.target, f)
+	case *UnionRule:
+		for _, b := range v.branches {
+			if matchConstraintType(b, f) {
+				return true
 			}
-			rule.constraint = ruler
+		}
+		return false
+	case Ruler:
+		switch v.RuleType() {
+		case RuleTypeObj:
+			return f.Kind() == FieldKindMapping
+		case RuleTypeArr, RuleTypeSeq:
+			return f.Tag() == yamlNodeTypeSeq
+		}
+		return matchScalarType(string(v.RuleType()), f)
+	}
+	return false
+}
 
-		} else if validStrNode(value) {
-			if !contains(scalarTypes, value.Value) {
-				return errors.New(fmt.Sprintf("constraint should be one of %v", value.Value))
-			} else {
-				rule.constraint = value.Value
+// matchConstraint check whether field f passes constraint c, without affecting validation in progress
+func matchConstraint(ctx context.Context, c Constraint, f Field) bool {
+	switch v := c.(type) {
+	case string:
+		return matchScalarType(v, f)
+	case Ruler:
+		return matchRule(ctx, v, f)
+	}
+	return false
+}
+
+// matchRule check whether field f passes rule r, without affecting validation in progress
+func matchRule(ctx context.Context, r Ruler, f Field) bool {
+	switch r.RuleType() {
+	case RuleTypeObj:
+		if f.Kind() != FieldKindMapping {
+			return false
+		}
+	case RuleTypeArr, RuleTypeSeq:
+		if f.Kind() != FieldKindSequence {
+			return false
+		}
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	result := validateRule(ctx, cancel, r, f, nil)
+	return len(*result) == 0
+}
+
+// UnionRule represent a rule of field in one of several types, each type is a branch with its own constraints
+type UnionRule struct {
+	Rule
+	branches []Ruler
+}
+
+func (rule *UnionRule) GetBranches() []Ruler {
+	return rule.branches
+}
+
+func (rule *UnionRule) restructure() error {
+	err := rule.Rule.restructure()
+	if err != nil {
+		return err
+	}
+
+	_, value, _ := GetKVNodeByKeyName(ConstraintKeyType, rule.getContent())
+	if len(value.Content) == 0 {
+		return errors.New(fmt.Sprintf("type not found : [%s]", rule.Key()))
+	}
+	for i := range value.Content {
+		node := value.Content[i]
+		//branch in type name only, eg,. [$int, $str]
+		if validStrNode(node) {
+			node = &yaml.Node{
+				Kind: yaml.MappingNode,
+				Tag:  yamlNodeTypeMap,
+				Content: []*yaml.Node{
+					{Kind: yaml.ScalarNode, Tag: yamlNodeTypeStr, Value: ConstraintKeyType},
+					node,
+				},
 			}
-		} else {
-			return errors.New("constraint format should be a string value of scalar type or obj")
 		}
-	} else {
-		return errors.New(fmt.Sprintf("constraint for key [%s] missing", rule.Key()))
+		if !validMapNode(node) {
+			return errors.New(fmt.Sprintf("type of union must be a type name or a rule : [%s.%d]", rule.Key(), i))
+		}
+
+		r, err := newRuler(rule.keyNode, node, false, rule.scope)
+		if err != nil {
+			return err
+		}
+		err = r.restructure()
+		if err != nil {
+			return err
+		}
+		rule.branches = append(rule.branches, r)
 	}
 	return nil
 }
 
+// types return type names of all branches, eg,. $int | $str
+func (rule *UnionRule) types() string {
+	types := pie.Map(rule.branches, func(r Ruler) string {
+		return string(r.RuleType())
+	})
+	return strings.Join(types, " | ")
+}
+
+// SeqRule represent a rule of list, which contains values in various types
+type SeqRule struct {
+	Rule
+	alternatives []Constraint //alternatives of element, element is valid if it matches one of the alternatives
+}
+
+func (rule *SeqRule) GetAlternatives() []Constraint {
+	return rule.alternatives
+}
+
+func (rule *SeqRule) restructure() error {
+	err := rule.Rule.restructure()
+	if err != nil {
+		return err
+	}
+
+	//constraint of seq is optional, elements in any type are accepted without it
+	key, value, exist := GetKVNodeByKeyName(ConstraintKeyConstraint, rule.getContent())
+	if key != nil && value != nil && exist {
+		if !validArrNode(value) {
+			return ConstraintTypeError(rule.Key(), yamlNodeTypeSeq)
+		}
+		for i := range value.Content {
+			c, err := newConstraint(key, value.Content[i], rule.scope)
+			if err != nil {
+				return err
+			}
+			rule.alternatives = append(rule.alternatives, c)
+		}
+	}
+	return nil
+}
+
+// match find the first alternative element f matches
+func (rule *SeqRule) match(ctx context.Context, f Field) (Constraint, bool) {
+	for i := range rule.alternatives {
+		if matchConstraint(ctx, rule.alternatives[i], f) {
+			return rule.alternatives[i], true
+		}
+	}
+	return nil, false
+}
+
+// matchType find the first alternative element f is in type of
+func (rule *SeqRule) matchType(f Field) (Constraint, bool) {
+	for i := range rule.alternatives {
+		if matchConstraintType(rule.alternatives[i], f) {
+			return rule.alternatives[i], true
+		}
+	}
+	return nil, false
+}
+
 type ScalarRule struct {
 	Rule
-	of []any
+	of    []any
+	ofTag []string //tags of values in of, only compared under type $any
 }
 
 func (rule *ScalarRule) restructure() error {
@@ -526,13 +1111,12 @@ func (rule *ScalarRule) restructure() error {
 		}
 		for i := range value.Content {
 			v := value.Content[i]
-			if v.Tag != getYAMLNodeTag(rule.ruleType) {
+			if !rule.validOfTag(v.Tag) {
 				k := fmt.Sprintf("%s.%d", rule.Key(), i)
 				return OfTypeError(k, string(rule.ruleType))
 			} else {
-				if rule.of == nil {
-					rule.of = append(rule.of, v.Value)
-				}
+				rule.of = append(rule.of, v.Value)
+				rule.ofTag = append(rule.ofTag, v.Tag)
 			}
 		}
 	}
@@ -540,12 +1124,72 @@ func (rule *ScalarRule) restructure() error {
 	return nil
 }
 
+// validOfTag check whether a value in constraint of with tag is acceptable for the rule
+func (rule *ScalarRule) validOfTag(tag string) bool {
+	if rule.ruleType == RuleTypeAny {
+		return contains(scalarTags, tag)
+	}
+	return tag == getYAMLNodeTag(rule.ruleType)
+}
+
+// validateOf check value of field f is one of the values in constraint of
+func (rule *ScalarRule) validateOf(f Field, result *[]*Result) *[]*Result {
+	if rule.of == nil || len(rule.of) == 0 {
+		return result
+	}
+
+	of := pie.Map(rule.of, func(t any) string {
+		return fmt.Sprintf("%v", t)
+	})
+	for i := range of {
+		//values in same literal but different type are not equal under type $any, eg. "1" and 1
+		if (rule.ruleType != RuleTypeAny || rule.ofTag[i] == f.Tag()) && equalScalar(rule.ofTag[i], of[i], f) {
+			return result
+		}
+	}
+	return appendResult(result, OfMismatch, OfContainError(f.Key(), rule.of), f.getValueRange())
+}
+
+// equalScalar check whether value in tag is equal to value of field f in decoded value instead of literal,
+// eg,. true and True, 1 and 0x1, null and ~
+func equalScalar(tag, value string, f Field) bool {
+	switch tag {
+	case yamlNodeTypeInt, yamlNodeTypeFloat:
+		x, err := parseYAMLRat(tag, value)
+		if err != nil {
+			return false
+		}
+		y, err := parseYAMLRat(f.Tag(), f.Value())
+		return err == nil && x.Cmp(y) == 0
+	case yamlNodeTypeBool:
+		var x, y bool
+		node := &yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: value}
+		return node.Decode(&x) == nil && f.getValueNode().Decode(&y) == nil && x == y
+	case yamlNodeTypeNull:
+		return f.Tag() == yamlNodeTypeNull
+	case yamlNodeTypeTimestamp:
+		var x, y time.Time
+		node := &yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: value}
+		return node.Decode(&x) == nil && f.getValueNode().Decode(&y) == nil && x.Equal(y)
+	}
+	return value == f.Value()
+}
+
 // StrRule represent a rule field of string
 type StrRule struct {
 	ScalarRule
 	max    uint           //max length of field
 	min    uint           //min length of field
 	regexp *regexp.Regexp //regexp of field
+
+	format     Format //format of field
+	formatName string
+
+	prefix     *string
+	suffix     *string
+	substring  *string //$contains
+	notRegexp  *regexp.Regexp
+	letterCase *string
 }
 
 func (rule *StrRule) GetMax() uint {
@@ -593,7 +1237,22 @@ func (rule *StrRule) restructure() error {
 		rule.regexp = reg
 	}
 
-	return nil
+	//check format
+	key, value, exist = GetKVNodeByKeyName(ConstraintKeyFormat, rule.getContent())
+	if key != nil && value != nil && exist {
+		if !validStrNode(value) {
+			return errors.New(fmt.Sprintf("value node must be string : [%s]", key.Value))
+		}
+		format, found := getFormat(value.Value)
+		if !found {
+			return errors.New(fmt.Sprintf("format not found : [%s]", value.Value))
+		}
+		rule.format = format
+		rule.formatName = value.Value
+	}
+
+	//check prefix, suffix, substring, negative regexp and letter case
+	return rule.newAffixes()
 }
 
 // BoolRule represent a rule of boolean
@@ -605,21 +1264,158 @@ func (rule *BoolRule) restructure() error {
 	return rule.ScalarRule.restructure()
 }
 
+// NumberRule represent a rule of number, which is the base of IntRule and FloatRule
+type NumberRule struct {
+	ScalarRule
+	numRange    *numRange //range of number
+	multipleOf  *decimal
+	maxDecimals *int //maximum number of decimal places of float
+}
+
+func (rule *NumberRule) restructure() error {
+	err := rule.ScalarRule.restructure()
+	if err != nil {
+		return err
+	}
+
+	//check range
+	key, value, exist := GetKVNodeByKeyName(ConstraintKeyRange, rule.getContent())
+	if key != nil && value != nil && exist {
+		if !validMapNode(value) {
+			return ConstraintTypeError(ConstraintKeyRange, yamlNodeTypeMap)
+		}
+		r, err := rule.newRange(value)
+		if err != nil {
+			return err
+		}
+		rule.numRange = r
+	}
+
+	//check multiple and decimal places
+	return rule.newDecimals()
+}
+
+// newRange parse bounds of $range
+func (rule *NumberRule) newRange(node *yaml.Node) (*numRange, error) {
+	r := &numRange{}
+	for _, bound := range []string{ConstraintKeyMin, ConstraintKeyExMin, ConstraintKeyMax, ConstraintKeyExMax} {
+		k, v, e := GetKVNodeByKeyName(bound, node.Content)
+		if !(k != nil && v != nil && e) {
+			continue
+		}
+		//bound of float could be written in int
+		if !(validIntNode(v) || (rule.ruleType == RuleTypeFloat && validFloatNode(v))) {
+			return nil, ConstraintTypeError(fmt.Sprintf("%s.%s", rule.Key(), bound), string(rule.ruleType))
+		}
+		n, err := parseYAMLNumber(v.Tag, v.Value)
+		if err != nil {
+			return nil, err
+		}
+
+		switch bound {
+		case ConstraintKeyMin, ConstraintKeyExMin:
+			if r.min != nil {
+				return nil, errors.New(fmt.Sprintf("only one of %s and %s is allowed : [%s]",
+					ConstraintKeyMin, ConstraintKeyExMin, rule.Key()))
+			}
+			r.min, r.minExclusive = n, bound == ConstraintKeyExMin
+		case ConstraintKeyMax, ConstraintKeyExMax:
+			if r.max != nil {
+				return nil, errors.New(fmt.Sprintf("only one of %s and %s is allowed : [%s]",
+					ConstraintKeyMax, ConstraintKeyExMax, rule.Key()))
+			}
+			r.max, r.maxExclusive = n, bound == ConstraintKeyExMax
+		}
+	}
+
+	if r.min == nil && r.max == nil {
+		return nil, errors.New(fmt.Sprintf("bound of range missing : [%s]", rule.Key()))
+	}
+	if r.min != nil && r.max != nil && r.min.Cmp(r.max) > 0 {
+		return nil, errors.New(fmt.Sprintf("minimum of range must not be greater than maximum : [%s]", rule.Key()))
+	}
+	return r, nil
+}
+
+// validateRange check value of field f is inside range
+func (rule *NumberRule) validateRange(f Field, result *[]*Result) *[]*Result {
+	if rule.numRange == nil {
+		return result
+	}
+	n, err := parseYAMLNumber(f.Tag(), f.Value())
+	if err != nil || !rule.numRange.contains(n) {
+		return appendResult(result, RangeMismatch, NewRangeError(f.Key(), rule.numRange.String()), f.getValueRange())
+	}
+	return result
+}
+
+// numRange represent range of number, bound is omitted while it's nil
+type numRange struct {
+	min          *big.Float
+	max          *big.Float
+	minExclusive bool
+	maxExclusive bool
+}
+
+func (r *numRange) contains(n *big.Float) bool {
+	if r.min != nil {
+		c := n.Cmp(r.min)
+		if c < 0 || (c == 0 && r.minExclusive) {
+			return false
+		}
+	}
+	if r.max != nil {
+		c := n.Cmp(r.max)
+		if c > 0 || (c == 0 && r.maxExclusive) {
+			return false
+		}
+	}
+	return true
+}
+
+// String return range in interval notation, eg,. (0, 1]
+func (r *numRange) String() string {
+	left, right := "[", "]"
+	min, max := "-inf", "+inf"
+	if r.min != nil && !r.min.IsInf() {
+		min = r.min.Text('g', -1)
+	}
+	if r.min == nil || r.minExclusive {
+		left = "("
+	}
+	if r.max != nil && !r.max.IsInf() {
+		max = r.max.Text('g', -1)
+	}
+	if r.max == nil || r.maxExclusive {
+		right = ")"
+	}
+	return fmt.Sprintf("%s%s, %s%s", left, min, max, right)
+}
+
 // FloatRule represent a rule a float
 type FloatRule struct {
-	ScalarRule
+	NumberRule
 }
 
 func (rule *FloatRule) restructure() error {
-	return rule.ScalarRule.restructure()
+	return rule.NumberRule.restructure()
 }
 
 // IntRule represent a rule of int
 type IntRule struct {
-	ScalarRule
+	NumberRule
 }
 
 func (rule *IntRule) restructure() error {
+	return rule.NumberRule.restructure()
+}
+
+// AnyRule represent a rule of any scalar value, include bool, int, float, string and null
+type AnyRule struct {
+	ScalarRule
+}
+
+func (rule *AnyRule) restructure() error {
 	return rule.ScalarRule.restructure()
 }
 
@@ -632,25 +1428,56 @@ func (rule *NullFieldRule) restructure() error {
 	return rule.ScalarRule.restructure()
 }
 
-func newRuler(keyNode, valueNode *yaml.Node, document bool) (Ruler, error) {
+func newRuler(keyNode, valueNode *yaml.Node, document bool, scope *ruleScope) (Ruler, error) {
 	if !validMapNode(valueNode) {
 		return nil, errors.New(fmt.Sprintf("value node must be map : [%s]", keyNode.Value))
 	}
 
+	//reference to definition
+	if k, v, e := GetKVNodeByKeyName(ConstraintKeyUse, valueNode.Content); k != nil && v != nil && e {
+		return &RefRule{
+			Rule: Rule{
+				keyNode:   keyNode,
+				valueNode: valueNode,
+				scope:     scope,
+			}}, nil
+	}
+
 	if document {
 		return &ObjRule{
 			Rule: Rule{
 				ruleType:  RuleTypeObj,
 				keyNode:   keyNode,
 				valueNode: valueNode,
+				scope:     scope,
 			}}, nil
 	}
 
 	k, v, e := GetKVNodeByKeyName(ConstraintKeyType, valueNode.Content)
 	if !(k != nil && v != nil && e) {
+		//type of rule extends others could be omitted, which is $obj
+		if k, _, e := GetKVNodeByKeyName(ConstraintKeyExtends, valueNode.Content); k != nil && e {
+			return &ObjRule{
+				Rule: Rule{
+					ruleType:  RuleTypeObj,
+					keyNode:   keyNode,
+					valueNode: valueNode,
+					scope:     scope,
+				}}, nil
+		}
 		return nil, errors.New(fmt.Sprintf("type not found : [%s]", keyNode.Value))
 	}
 
+	if validArrNode(v) {
+		return &UnionRule{
+			Rule: Rule{
+				ruleType:  RuleTypeUnion,
+				keyNode:   keyNode,
+				valueNode: valueNode,
+				scope:     scope,
+			}}, nil
+	}
+
 	switch RuleType(v.Value) {
 	case RuleTypeArr:
 		return &ArrRule{
@@ -658,23 +1485,44 @@ func newRuler(keyNode, valueNode *yaml.Node, document bool) (Ruler, error) {
 				ruleType:  RuleTypeArr,
 				keyNode:   keyNode,
 				valueNode: valueNode,
+				scope:     scope,
+			}}, nil
+	case RuleTypeSeq:
+		return &SeqRule{
+			Rule: Rule{
+				ruleType:  RuleTypeSeq,
+				keyNode:   keyNode,
+				valueNode: valueNode,
+				scope:     scope,
+			}}, nil
+	case RuleTypeAny:
+		return &AnyRule{
+			ScalarRule{
+				Rule: Rule{
+					ruleType:  RuleTypeAny,
+					keyNode:   keyNode,
+					valueNode: valueNode,
+					scope:     scope,
+				},
 			}}, nil
-	case RuleTypeSeq, RuleTypeAny:
-		//TODO : tbc
 	case RuleTypeObj:
 		return &ObjRule{
 			Rule: Rule{
 				ruleType:  RuleTypeObj,
 				keyNode:   keyNode,
 				valueNode: valueNode,
+				scope:     scope,
 			}}, nil
 	case RuleTypeInt:
 		return &IntRule{
-			ScalarRule{
-				Rule: Rule{
-					ruleType:  RuleTypeInt,
-					keyNode:   keyNode,
-					valueNode: valueNode,
+			NumberRule{
+				ScalarRule: ScalarRule{
+					Rule: Rule{
+						ruleType:  RuleTypeInt,
+						keyNode:   keyNode,
+						valueNode: valueNode,
+						scope:     scope,
+					},
 				},
 			}}, nil
 	case RuleTypeStr:
@@ -684,6 +1532,7 @@ func newRuler(keyNode, valueNode *yaml.Node, document bool) (Ruler, error) {
 					ruleType:  RuleTypeStr,
 					keyNode:   keyNode,
 					valueNode: valueNode,
+					scope:     scope,
 				},
 			}}, nil
 	case RuleTypeBool:
@@ -693,15 +1542,19 @@ func newRuler(keyNode, valueNode *yaml.Node, document bool) (Ruler, error) {
 					ruleType:  RuleTypeBool,
 					keyNode:   keyNode,
 					valueNode: valueNode,
+					scope:     scope,
 				},
 			}}, nil
 	case RuleTypeFloat:
 		return &FloatRule{
-			ScalarRule{
-				Rule: Rule{
-					ruleType:  RuleTypeFloat,
-					keyNode:   keyNode,
-					valueNode: valueNode,
+			NumberRule{
+				ScalarRule: ScalarRule{
+					Rule: Rule{
+						ruleType:  RuleTypeFloat,
+						keyNode:   keyNode,
+						valueNode: valueNode,
+						scope:     scope,
+					},
 				},
 			}}, nil
 	case RuleTypeNil:
@@ -711,6 +1564,7 @@ func newRuler(keyNode, valueNode *yaml.Node, document bool) (Ruler, error) {
 					ruleType:  RuleTypeNil,
 					keyNode:   keyNode,
 					valueNode: valueNode,
+					scope:     scope,
 				},
 			}}, nil
 
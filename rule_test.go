@@ -1,7 +1,10 @@
 package invalid
 
 import (
+	"errors"
 	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+	"math/big"
 	"os"
 	"path/filepath"
 	"testing"
@@ -12,6 +15,592 @@ func TestRule(t *testing.T) {
 	testConstraintOfInvalid(t)
 	testConstraintOfInvalid2(t)
 	testConstraintOfValid(t)
+	testRuleAny(t)
+	testRuleSeq(t)
+	testRuleRangeInvalid(t)
+	testRuleUnion(t)
+	testRuleDefinitions(t)
+	testRuleImport(t)
+	testRuleExtends(t)
+	testRuleConditions(t)
+	testRuleKeyGroups(t)
+	testRuleCardinality(t)
+	testRuleUnique(t)
+	testRuleItems(t)
+	testRuleContains(t)
+	testRuleDefaults(t)
+	testRuleFormat(t)
+	testRuleFunc(t)
+	testRuleAssert(t)
+	testRuleRefersTo(t)
+	testRuleDecimals(t)
+	testRuleStr(t)
+}
+
+func testRuleStr(t *testing.T) {
+	file, err := os.OpenFile(filepath.Join("test", "exam", "str.yaml"), os.O_RDONLY, os.ModeSticky)
+	assert.Nil(t, err)
+
+	rule, err := NewRule(file)
+	assert.Nil(t, err)
+	assert.NotNil(t, rule)
+
+	images := rule.MustGet("images").(*ArrRule).constraint.(Ruler)
+	image := images.MustGet("image").(*StrRule)
+	assert.EqualValues(t, "registry.example.com/", *image.prefix)
+	assert.EqualValues(t, ":", *image.substring)
+	assert.EqualValues(t, ":latest$", image.notRegexp.String())
+	assert.Nil(t, image.suffix)
+	assert.EqualValues(t, CaseKebab, *images.MustGet("name").(*StrRule).letterCase)
+
+	//unknown letter case is reported when rule is compiled
+	file, err = os.OpenFile(filepath.Join("test", "exam", "str", "case.yaml"), os.O_RDONLY, os.ModeSticky)
+	assert.Nil(t, err)
+	rule, err = NewRule(file)
+	assert.NotNil(t, err)
+	assert.Nil(t, rule)
+}
+
+func testRuleDecimals(t *testing.T) {
+	file, err := os.OpenFile(filepath.Join("test", "exam", "decimals.yaml"), os.O_RDONLY, os.ModeSticky)
+	assert.Nil(t, err)
+
+	rule, err := NewRule(file)
+	assert.Nil(t, err)
+	assert.NotNil(t, rule)
+
+	items := rule.MustGet("items").(*ArrRule).constraint.(Ruler)
+	step := items.MustGet("step").(*FloatRule)
+	assert.EqualValues(t, "0.1", step.multipleOf.text)
+	assert.EqualValues(t, big.NewRat(1, 10), step.multipleOf.value)
+	price := items.MustGet("price").(*FloatRule)
+	assert.EqualValues(t, 2, *price.maxDecimals)
+	assert.Nil(t, price.multipleOf)
+
+	//$max-decimals is valid under type $float only, and $multiple-of must be positive
+	for _, name := range []string{"int.yaml", "negative.yaml"} {
+		file, err = os.OpenFile(filepath.Join("test", "exam", "decimals", name), os.O_RDONLY, os.ModeSticky)
+		assert.Nil(t, err)
+		rule, err = NewRule(file)
+		assert.NotNil(t, err, name)
+		assert.Nil(t, rule)
+	}
+}
+
+func testRuleRefersTo(t *testing.T) {
+	file, err := os.OpenFile(filepath.Join("test", "exam", "refers_to.yaml"), os.O_RDONLY, os.ModeSticky)
+	assert.Nil(t, err)
+
+	rule, err := NewRule(file)
+	assert.Nil(t, err)
+	assert.NotNil(t, rule)
+
+	matchLabels := rule.MustGet("deployment").MustGet("spec").MustGet("selector").MustGet("matchLabels")
+	assert.EqualValues(t, ReferAsEntry, matchLabels.getReference().as)
+	assert.EqualValues(t, "deployment.spec.template.metadata.labels", matchLabels.getReference().String())
+	_, exist := matchLabels.Get(ConstraintKeyRefersTo)
+	assert.False(t, exist)
+
+	//a string is a shorthand of $path looked up as value
+	ports := rule.MustGet("service").MustGet("spec").MustGet("ports").(*ArrRule)
+	targetPort := ports.constraint.(Ruler).MustGet("targetPort")
+	assert.EqualValues(t, ReferAsValue, targetPort.getReference().as)
+	assert.EqualValues(t, []string{"deployment", "spec", "template", "spec", "containers", "*", "ports", "*", "name"},
+		targetPort.getReference().path)
+
+	//entry is valid under type $obj of definition in $use
+	podSelector := rule.MustGet("service").MustGet("spec").MustGet("podSelector")
+	assert.EqualValues(t, ReferAsEntry, podSelector.getReference().as)
+
+	//entry is valid under type $obj only
+	for _, name := range []string{"as.yaml", "as_use.yaml"} {
+		file, err = os.OpenFile(filepath.Join("test", "exam", "refers_to", name), os.O_RDONLY, os.ModeSticky)
+		assert.Nil(t, err)
+		rule, err = NewRule(file)
+		assert.NotNil(t, err, name)
+		assert.Nil(t, rule)
+	}
+}
+
+func testRuleAssert(t *testing.T) {
+	file, err := os.OpenFile(filepath.Join("test", "exam", "assert.yaml"), os.O_RDONLY, os.ModeSticky)
+	assert.Nil(t, err)
+
+	rule, err := NewRule(file)
+	assert.Nil(t, err)
+	assert.NotNil(t, rule)
+
+	campaign, _ := rule.Get("campaign")
+	asserts := campaign.(*ObjRule).asserts
+	assert.EqualValues(t, 2, len(asserts))
+	assert.EqualValues(t, "budget.daily * 30 <= budget.total", asserts[1].src)
+	_, exist := campaign.Get(ConstraintKeyAssert)
+	assert.False(t, exist)
+
+	//syntax error is reported when rule is compiled
+	file, err = os.OpenFile(filepath.Join("test", "exam", "assert", "syntax.yaml"), os.O_RDONLY, os.ModeSticky)
+	assert.Nil(t, err)
+	rule, err = NewRule(file)
+	assert.NotNil(t, err)
+	assert.Nil(t, rule)
+}
+
+func testRuleFunc(t *testing.T) {
+	file, err := os.OpenFile(filepath.Join("test", "exam", "func.yaml"), os.O_RDONLY, os.ModeSticky)
+	assert.Nil(t, err)
+
+	rule, err := NewRule(file)
+	assert.Nil(t, err)
+	assert.NotNil(t, rule)
+
+	replicas, _ := rule.Get("replicas")
+	assert.EqualValues(t, 1, len(replicas.getFuncs()))
+	assert.EqualValues(t, "even", replicas.getFuncs()[0].name)
+	_, exist := rule.MustGet("window").Get(ConstraintKeyFunc)
+	assert.False(t, exist)
+
+	//func not found
+	file, err = os.OpenFile(filepath.Join("test", "exam", "func", "unknown.yaml"), os.O_RDONLY, os.ModeSticky)
+	assert.Nil(t, err)
+	rule, err = NewRule(file)
+	assert.EqualValues(t, errors.New("func not found : [quartz]"), err)
+	assert.Nil(t, rule)
+
+	//value of $func is neither a name nor a list of names
+	for name, e := range map[string]string{
+		"map.yaml":  "value of $func must be a func name or a list of func names : [schedule]",
+		"name.yaml": "func name must be string : [schedule.$func.1]",
+	} {
+		file, err = os.OpenFile(filepath.Join("test", "exam", "func", name), os.O_RDONLY, os.ModeSticky)
+		assert.Nil(t, err)
+		rule, err = NewRule(file)
+		assert.EqualValues(t, errors.New(e), err, name)
+		assert.Nil(t, rule)
+	}
+}
+
+func testRuleFormat(t *testing.T) {
+	file, err := os.OpenFile(filepath.Join("test", "exam", "format", "unknown.yaml"), os.O_RDONLY, os.ModeSticky)
+	assert.Nil(t, err)
+
+	rule, err := NewRule(file)
+	assert.EqualValues(t, errors.New("format not found : [rgb]"), err)
+	assert.Nil(t, rule)
+}
+
+func testRuleDefaults(t *testing.T) {
+	file, err := os.OpenFile(filepath.Join("test", "exam", "defaults.yaml"), os.O_RDONLY, os.ModeSticky)
+	assert.Nil(t, err)
+
+	rule, err := NewRule(file)
+	assert.Nil(t, err)
+	assert.NotNil(t, rule)
+
+	server, _ := rule.Get("server")
+	port, _ := server.Get("port")
+	assert.EqualValues(t, "8080", port.getDefault().Value)
+	tls, _ := server.Get("tls")
+	assert.EqualValues(t, yaml.MappingNode, tls.getDefault().Kind)
+	_, exist := tls.Get(ConstraintKeyDefault)
+	assert.False(t, exist)
+
+	//default is validated against its rule
+	file, err = os.OpenFile(filepath.Join("test", "exam", "defaults", "invalid.yaml"), os.O_RDONLY, os.ModeSticky)
+	assert.Nil(t, err)
+	rule, err = NewRule(file)
+	assert.EqualValues(t, NewDefaultError("port", NewRangeError("port", "[1, +inf)")), err)
+	assert.Nil(t, rule)
+
+	//default of required field
+	file, err = os.OpenFile(filepath.Join("test", "exam", "defaults", "required.yaml"), os.O_RDONLY, os.ModeSticky)
+	assert.Nil(t, err)
+	rule, err = NewRule(file)
+	assert.NotNil(t, err)
+	assert.Nil(t, rule)
+
+	//default of item and $use is validated, default of pattern field and definition never applies
+	for _, name := range []string{"items.yaml", "definition_use.yaml", "pattern.yaml", "definition.yaml"} {
+		file, err = os.OpenFile(filepath.Join("test", "exam", "defaults", name), os.O_RDONLY, os.ModeSticky)
+		assert.Nil(t, err)
+		rule, err = NewRule(file)
+		assert.NotNil(t, err, name)
+		assert.Nil(t, rule, name)
+	}
+}
+
+func testRuleContains(t *testing.T) {
+	file, err := os.OpenFile(filepath.Join("test", "exam", "contains.yaml"), os.O_RDONLY, os.ModeSticky)
+	assert.Nil(t, err)
+
+	rule, err := NewRule(file)
+	assert.Nil(t, err)
+	assert.NotNil(t, rule)
+
+	env, _ := rule.Get("env")
+	arr := env.(*ArrRule)
+	assert.Nil(t, arr.GetConstraint())
+	assert.EqualValues(t, RuleTypeObj, arr.contains.RuleType())
+	assert.EqualValues(t, 1, *arr.containsCount.min)
+	assert.Nil(t, arr.containsCount.max)
+
+	args, _ := rule.Get("args")
+	assert.EqualValues(t, string(RuleTypeStr), args.(*ArrRule).GetConstraint())
+	assert.Nil(t, args.(*ArrRule).containsCount.min)
+	assert.EqualValues(t, 1, *args.(*ArrRule).containsCount.max)
+
+	//invalid contains
+	for _, name := range []string{"min.yaml", "bounds.yaml"} {
+		file, err = os.OpenFile(filepath.Join("test", "exam", "contains", name), os.O_RDONLY, os.ModeSticky)
+		assert.Nil(t, err)
+		rule, err = NewRule(file)
+		assert.NotNil(t, err, name)
+		assert.Nil(t, rule)
+	}
+}
+
+func testRuleItems(t *testing.T) {
+	file, err := os.OpenFile(filepath.Join("test", "exam", "items.yaml"), os.O_RDONLY, os.ModeSticky)
+	assert.Nil(t, err)
+
+	rule, err := NewRule(file)
+	assert.Nil(t, err)
+	assert.NotNil(t, rule)
+
+	backends, _ := rule.Get("backends")
+	arr := backends.(*ArrRule)
+	assert.Nil(t, arr.GetConstraint())
+	assert.EqualValues(t, 3, len(arr.items))
+	assert.EqualValues(t, RuleTypeStr, arr.items[0].RuleType())
+	assert.EqualValues(t, "backends.1", arr.items[1].Key())
+	assert.False(t, arr.items[2].Required())
+	assert.False(t, arr.additionalAllowed)
+
+	mirror, _ := rule.Get("mirror")
+	assert.True(t, mirror.(*ArrRule).additionalAllowed)
+	assert.EqualValues(t, RuleTypeInt, mirror.(*ArrRule).additionalItems.RuleType())
+
+	//invalid items
+	for _, name := range []string{"exclusive.yaml", "optional.yaml", "additional.yaml"} {
+		file, err = os.OpenFile(filepath.Join("test", "exam", "items", name), os.O_RDONLY, os.ModeSticky)
+		assert.Nil(t, err)
+		rule, err = NewRule(file)
+		assert.NotNil(t, err, name)
+		assert.Nil(t, rule)
+	}
+}
+
+func testRuleUnique(t *testing.T) {
+	file, err := os.OpenFile(filepath.Join("test", "exam", "unique.yaml"), os.O_RDONLY, os.ModeSticky)
+	assert.Nil(t, err)
+
+	rule, err := NewRule(file)
+	assert.Nil(t, err)
+	assert.NotNil(t, rule)
+
+	ports, _ := rule.Get("ports")
+	assert.True(t, ports.(*ArrRule).unique)
+	assert.Nil(t, ports.(*ArrRule).uniqueBy)
+	containers, _ := rule.Get("containers")
+	assert.False(t, containers.(*ArrRule).unique)
+	assert.EqualValues(t, []string{"name"}, containers.(*ArrRule).uniqueBy)
+	tags, _ := rule.Get("tags")
+	assert.EqualValues(t, []string{"name"}, tags.(*ArrRule).uniqueBy)
+}
+
+func testRuleCardinality(t *testing.T) {
+	file, err := os.OpenFile(filepath.Join("test", "exam", "cardinality.yaml"), os.O_RDONLY, os.ModeSticky)
+	assert.Nil(t, err)
+
+	rule, err := NewRule(file)
+	assert.Nil(t, err)
+	assert.NotNil(t, rule)
+
+	ports, _ := rule.Get("ports")
+	items := ports.(*ArrRule).itemCount
+	assert.EqualValues(t, 1, *items.min)
+	assert.EqualValues(t, 2, *items.max)
+	tags, _ := rule.Get("tags")
+	assert.Nil(t, tags.(*ArrRule).itemCount.min)
+	containers, _ := rule.Get("containers")
+	assert.Nil(t, containers.(*ArrRule).itemCount.max)
+	labels, _ := rule.Get("labels")
+	assert.EqualValues(t, 1, *labels.(*ObjRule).keys.min)
+	assert.EqualValues(t, 2, *labels.(*ObjRule).keys.max)
+
+	//invalid bounds
+	for _, name := range []string{"negative.yaml", "bounds.yaml"} {
+		file, err = os.OpenFile(filepath.Join("test", "exam", "cardinality", name), os.O_RDONLY, os.ModeSticky)
+		assert.Nil(t, err)
+		rule, err = NewRule(file)
+		assert.NotNil(t, err, name)
+		assert.Nil(t, rule)
+	}
+}
+
+func testRuleKeyGroups(t *testing.T) {
+	file, err := os.OpenFile(filepath.Join("test", "exam", "key_groups.yaml"), os.O_RDONLY, os.ModeSticky)
+	assert.Nil(t, err)
+
+	rule, err := NewRule(file)
+	assert.Nil(t, err)
+	assert.NotNil(t, rule)
+
+	services, _ := rule.Get("services")
+	service, _ := services.(*ObjRule).GetPatternRule("web")
+	groups := service.(*ObjRule).keyGroups
+	assert.EqualValues(t, 1, len(groups.dependencies))
+	assert.EqualValues(t, []string{"certFile", "keyFile"}, groups.dependencies[0].requires)
+	assert.EqualValues(t, [][]string{{"image", "build"}}, groups.oneOf)
+	assert.EqualValues(t, [][]string{{"command", "entrypoint"}}, groups.anyOf)
+	assert.EqualValues(t, [][]string{{"links", "external_links"}}, groups.noneOf)
+
+	//invalid key groups
+	for _, name := range []string{"dependencies.yaml", "one_of.yaml"} {
+		file, err = os.OpenFile(filepath.Join("test", "exam", "key_groups", name), os.O_RDONLY, os.ModeSticky)
+		assert.Nil(t, err)
+		rule, err = NewRule(file)
+		assert.NotNil(t, err, name)
+		assert.Nil(t, rule)
+	}
+}
+
+func testRuleConditions(t *testing.T) {
+	file, err := os.OpenFile(filepath.Join("test", "exam", "conditions.yaml"), os.O_RDONLY, os.ModeSticky)
+	assert.Nil(t, err)
+
+	rule, err := NewRule(file)
+	assert.Nil(t, err)
+	assert.NotNil(t, rule)
+
+	lb, _ := rule.Get("lb")
+	service := lb.(*RefRule).GetTarget().(*ObjRule)
+	assert.EqualValues(t, 4, len(service.conditions))
+	assert.EqualValues(t, "type == LoadBalancer", service.conditions[0].String())
+	assert.EqualValues(t, "type in [NodePort LoadBalancer]", service.conditions[2].String())
+	assert.EqualValues(t, 4, len(service.GetRules()))
+
+	//invalid conditions
+	for _, name := range []string{"override.yaml", "test.yaml", "branch.yaml"} {
+		file, err = os.OpenFile(filepath.Join("test", "exam", "conditions", name), os.O_RDONLY, os.ModeSticky)
+		assert.Nil(t, err)
+		rule, err = NewRule(file)
+		assert.NotNil(t, err, name)
+		assert.Nil(t, rule)
+	}
+}
+
+func testRuleExtends(t *testing.T) {
+	file, err := os.OpenFile(filepath.Join("test", "exam", "extends.yaml"), os.O_RDONLY, os.ModeSticky)
+	assert.Nil(t, err)
+
+	rule, err := NewRule(file)
+	assert.Nil(t, err)
+	assert.NotNil(t, rule)
+
+	deployment, _ := rule.Get("deployment")
+	assert.EqualValues(t, RuleTypeObj, deployment.RuleType())
+	assert.EqualValues(t, 6, len(deployment.GetRules()))
+	_, exist := deployment.Get("paused")
+	assert.False(t, exist)
+	replicas, _ := deployment.Get("replicas")
+	assert.EqualValues(t, RuleTypeInt, replicas.RuleType())
+	name, _ := deployment.Get("name")
+	assert.EqualValues(t, 20, name.(*StrRule).max)
+	//optional fields are overridden to be required, range is tightened
+	timeout, _ := deployment.Get("timeout")
+	assert.True(t, timeout.Required())
+	assert.True(t, timeout.(*IntRule).numRange.minExclusive)
+	image, _ := deployment.Get("image")
+	assert.True(t, image.Required())
+
+	//base is not modified
+	file, err = os.OpenFile(filepath.Join("test", "exam", "extends.yaml"), os.O_RDONLY, os.ModeSticky)
+	assert.Nil(t, err)
+	rule, err = NewRule(file)
+	assert.Nil(t, err)
+	scope := rule.(*ObjRule).scope
+	base, _ := scope.resolve("Deployment")
+	assert.EqualValues(t, 6, len(base.GetRules()))
+	timeout, _ = base.Get("timeout")
+	assert.False(t, timeout.Required())
+
+	//ambiguous merges
+	for _, name := range []string{"widen.yaml", "type.yaml", "multiple.yaml", "remove.yaml", "range.yaml",
+		"cardinality.yaml", "reg.yaml", "optional.yaml"} {
+		file, err = os.OpenFile(filepath.Join("test", "exam", "extends", name), os.O_RDONLY, os.ModeSticky)
+		assert.Nil(t, err)
+		rule, err = NewRule(file)
+		assert.NotNil(t, err, name)
+		assert.Nil(t, rule)
+	}
+}
+
+func testRuleImport(t *testing.T) {
+	fsys := os.DirFS(filepath.Join("test", "exam", "import"))
+	rule, err := NewRuleFS(fsys, "service.yaml")
+	assert.Nil(t, err)
+	assert.NotNil(t, rule)
+
+	metadata, _ := rule.Get("metadata")
+	assert.EqualValues(t, RuleTypeObj, metadata.RuleType())
+	labels, _ := metadata.Get("labels")
+	assert.EqualValues(t, RuleTypeObj, labels.RuleType())
+	assert.EqualValues(t, "Labels", labels.(*RefRule).GetName())
+
+	//import cycle
+	rule, err = NewRuleFS(fsys, "cycle/a.yaml")
+	assert.EqualValues(t, NewImportCycleError("cycle/b.yaml", 3, []string{"cycle/a.yaml", "cycle/b.yaml", "cycle/a.yaml"}), err)
+	assert.Nil(t, rule)
+
+	//import without resolver
+	file, err := fsys.Open("service.yaml")
+	assert.Nil(t, err)
+	rule, err = NewRule(file)
+	assert.NotNil(t, err)
+	assert.Nil(t, rule)
+}
+
+func testRuleDefinitions(t *testing.T) {
+	file, err := os.OpenFile(filepath.Join("test", "exam", "definitions.yaml"), os.O_RDONLY, os.ModeSticky)
+	assert.Nil(t, err)
+	assert.NotNil(t, file)
+
+	rule, err := NewRule(file)
+	assert.Nil(t, err)
+	assert.NotNil(t, rule)
+
+	_, exist := rule.Get(ConstraintKeyDefinitions)
+	assert.False(t, exist)
+
+	menu, _ := rule.Get("menu")
+	assert.EqualValues(t, RuleTypeObj, menu.RuleType())
+	assert.True(t, menu.Required())
+	menuX, valid := menu.(*RefRule)
+	assert.True(t, valid)
+	assert.EqualValues(t, "Menu", menuX.GetName())
+
+	//recursive definition
+	children, _ := menu.Get("children")
+	assert.EqualValues(t, RuleTypeArr, children.RuleType())
+	assert.False(t, children.Required())
+	constraint := children.(*ArrRule).GetConstraint().(*RefRule)
+	assert.Equal(t, menuX.GetTarget(), constraint.GetTarget())
+
+	about, _ := rule.Get("about")
+	assert.EqualValues(t, RuleTypeStr, about.RuleType())
+	assert.False(t, about.Required())
+
+	//circular reference
+	file, err = os.OpenFile(filepath.Join("test", "exam", "definitions_circular.yaml"), os.O_RDONLY, os.ModeSticky)
+	assert.Nil(t, err)
+	rule, err = NewRule(file)
+	assert.NotNil(t, err)
+	assert.Nil(t, rule)
+
+	//definition not found
+	file, err = os.OpenFile(filepath.Join("test", "exam", "definitions_missing.yaml"), os.O_RDONLY, os.ModeSticky)
+	assert.Nil(t, err)
+	rule, err = NewRule(file)
+	assert.EqualValues(t, errors.New("definition not found : [NotExist]"), err)
+	assert.Nil(t, rule)
+}
+
+func testRuleUnion(t *testing.T) {
+	file, err := os.OpenFile(filepath.Join("test", "exam", "union.yaml"), os.O_RDONLY, os.ModeSticky)
+	assert.Nil(t, err)
+	assert.NotNil(t, file)
+
+	rule, err := NewRule(file)
+	assert.Nil(t, err)
+	assert.NotNil(t, rule)
+
+	server, _ := rule.Get("server")
+	timeout, _ := server.Get("timeout")
+	assert.EqualValues(t, RuleTypeUnion, timeout.RuleType())
+	assert.True(t, timeout.Required())
+
+	timeoutX, valid := timeout.(*UnionRule)
+	assert.True(t, valid)
+	assert.EqualValues(t, 2, len(timeoutX.GetBranches()))
+	assert.EqualValues(t, RuleTypeInt, timeoutX.GetBranches()[0].RuleType())
+	assert.EqualValues(t, "timeout", timeoutX.GetBranches()[0].Key())
+	assert.EqualValues(t, RuleTypeStr, timeoutX.GetBranches()[1].RuleType())
+	assert.EqualValues(t, "^[0-9]+s$", timeoutX.GetBranches()[1].(*StrRule).GetReg().String())
+
+	tls3, _ := server.Get("tls3")
+	assert.False(t, tls3.Required())
+}
+
+func testRuleRangeInvalid(t *testing.T) {
+	file, err := os.OpenFile(filepath.Join("test", "exam", "range_invalid.yaml"), os.O_RDONLY, os.ModeSticky)
+	assert.Nil(t, err)
+	assert.NotNil(t, file)
+
+	rule, err := NewRule(file)
+	assert.NotNil(t, err)
+	assert.Nil(t, rule)
+}
+
+func testRuleSeq(t *testing.T) {
+	file, err := os.OpenFile(filepath.Join("test", "exam", "seq.yaml"), os.O_RDONLY, os.ModeSticky)
+	assert.Nil(t, err)
+	assert.NotNil(t, file)
+
+	rule, err := NewRule(file)
+	assert.Nil(t, err)
+	assert.NotNil(t, rule)
+
+	m, _ := rule.Get("map")
+	list, _ := m.Get("list")
+	assert.NotNil(t, list)
+	assert.EqualValues(t, RuleTypeSeq, list.RuleType())
+
+	listX, valid := list.(*SeqRule)
+	assert.True(t, valid)
+	assert.EqualValues(t, 4, len(listX.GetAlternatives()))
+	assert.EqualValues(t, RuleTypeInt, listX.GetAlternatives()[0])
+	assert.EqualValues(t, RuleTypeStr, listX.GetAlternatives()[1])
+	assert.EqualValues(t, RuleTypeObj, listX.GetAlternatives()[2].(Ruler).RuleType())
+	//alternatives in same type are described by their required keys
+	assert.EqualValues(t, "$obj{foo,bar}", describeConstraint(listX.GetAlternatives()[2]))
+	assert.EqualValues(t, "$obj{name}", describeConstraint(listX.GetAlternatives()[3]))
+
+	list2, _ := m.Get("list2")
+	list2X, valid := list2.(*SeqRule)
+	assert.True(t, valid)
+	assert.EqualValues(t, 0, len(list2X.GetAlternatives()))
+
+	//$type of alternative is honored
+	matrix, _ := m.Get("matrix")
+	matrixX, valid := matrix.(*SeqRule)
+	assert.True(t, valid)
+	assert.EqualValues(t, RuleTypeArr, matrixX.GetAlternatives()[1].(Ruler).RuleType())
+	assert.EqualValues(t, RuleTypeInt, matrixX.GetAlternatives()[1].(*ArrRule).GetConstraint())
+}
+
+func testRuleAny(t *testing.T) {
+	file, err := os.OpenFile(filepath.Join("test", "exam", "any.yaml"), os.O_RDONLY, os.ModeSticky)
+	assert.Nil(t, err)
+	assert.NotNil(t, file)
+
+	rule, err := NewRule(file)
+	assert.Nil(t, err)
+	assert.NotNil(t, rule)
+
+	m, _ := rule.Get("map")
+	ofVal, _ := m.Get("ofVal")
+	assert.NotNil(t, ofVal)
+	assert.EqualValues(t, RuleTypeAny, ofVal.RuleType())
+
+	ofValX, valid := ofVal.(*AnyRule)
+	assert.True(t, valid)
+	assert.EqualValues(t, []any{"1", "on", "true"}, ofValX.of)
+	assert.EqualValues(t, []string{yamlNodeTypeInt, yamlNodeTypeStr, yamlNodeTypeBool}, ofValX.ofTag)
+
+	list, _ := m.Get("list")
+	listX, valid := list.(*ArrRule)
+	assert.True(t, valid)
+	assert.EqualValues(t, RuleTypeAny, listX.constraint)
 }
 
 func testConstraintOfInvalid(t *testing.T) {
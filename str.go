@@ -0,0 +1,114 @@
+package invalid
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const (
+	ConstraintKeyPrefix = `$prefix`  //string must start with the value, valid under type $str
+	ConstraintKeySuffix = `$suffix`  //string must end with the value, valid under type $str
+	ConstraintKeyNotReg = `$not-reg` //regexp pattern which string must not match, valid under type $str
+	ConstraintKeyCase   = `$case`    //letter case of string, valid under type $str
+)
+
+// letter cases of $case
+const (
+	CaseLower = "lower" //no upper case letter, eg,. my-app
+	CaseUpper = "upper" //no lower case letter, eg,. LOG_LEVEL
+	CaseCamel = "camel" //eg,. maxReplicas
+	CaseSnake = "snake" //eg,. max_replicas
+	CaseKebab = "kebab" //eg,. max-replicas
+)
+
+var (
+	camelCaseReg = regexp.MustCompile(`^[a-z][a-zA-Z0-9]*$`)
+	snakeCaseReg = regexp.MustCompile(`^[a-z][a-z0-9]*(_[a-z0-9]+)*$`)
+	kebabCaseReg = regexp.MustCompile(`^[a-z][a-z0-9]*(-[a-z0-9]+)*$`)
+)
+
+// newAffixes read $prefix, $suffix, $contains, $not-reg and $case of str rule
+func (rule *StrRule) newAffixes() error {
+	for _, c := range []struct {
+		name  string
+		value **string
+	}{
+		{ConstraintKeyPrefix, &rule.prefix},
+		{ConstraintKeySuffix, &rule.suffix},
+		{ConstraintKeyContains, &rule.substring},
+		{ConstraintKeyCase, &rule.letterCase},
+	} {
+		k, v, e := GetKVNodeByKeyName(c.name, rule.getContent())
+		if !(k != nil && v != nil && e) {
+			continue
+		}
+		if !validStrNode(v) {
+			return errors.New(fmt.Sprintf("value node must be string : [%s]", k.Value))
+		}
+		value := v.Value
+		*c.value = &value
+	}
+
+	if rule.letterCase != nil && !contains([]string{CaseLower, CaseUpper, CaseCamel, CaseSnake, CaseKebab},
+		*rule.letterCase) {
+		return errors.New(fmt.Sprintf("value of %s must be one of [%s %s %s %s %s] : [%s]", ConstraintKeyCase,
+			CaseLower, CaseUpper, CaseCamel, CaseSnake, CaseKebab, rule.Key()))
+	}
+
+	k, v, e := GetKVNodeByKeyName(ConstraintKeyNotReg, rule.getContent())
+	if k != nil && v != nil && e {
+		if !validStrNode(v) {
+			return errors.New(fmt.Sprintf("value node must be string : [%s]", k.Value))
+		}
+		reg, err := regexp.Compile(v.Value)
+		if err != nil {
+			return errors.New(fmt.Sprintf("compile regexp error : [%s]", k.Value))
+		}
+		rule.notRegexp = reg
+	}
+	return nil
+}
+
+// inCase check whether value is in letter case c
+func inCase(value, c string) bool {
+	switch c {
+	case CaseLower:
+		return value == strings.ToLower(value)
+	case CaseUpper:
+		return value == strings.ToUpper(value)
+	case CaseCamel:
+		return camelCaseReg.MatchString(value)
+	case CaseSnake:
+		return snakeCaseReg.MatchString(value)
+	case CaseKebab:
+		return kebabCaseReg.MatchString(value)
+	}
+	return false
+}
+
+// validateAffixes check value of field f against $prefix, $suffix, $contains, $not-reg and $case
+func (rule *StrRule) validateAffixes(f Field, result *[]*Result) *[]*Result {
+	if f.Tag() != yamlNodeTypeStr {
+		return result
+	}
+	value := f.Value()
+	if rule.prefix != nil && !strings.HasPrefix(value, *rule.prefix) {
+		result = appendResult(result, PrefixMismatch, NewPrefixError(f.Key(), *rule.prefix), f.getValueRange())
+	}
+	if rule.suffix != nil && !strings.HasSuffix(value, *rule.suffix) {
+		result = appendResult(result, SuffixMismatch, NewSuffixError(f.Key(), *rule.suffix), f.getValueRange())
+	}
+	if rule.substring != nil && !strings.Contains(value, *rule.substring) {
+		result = appendResult(result, SubstringMismatch, NewSubstringError(f.Key(), *rule.substring), f.getValueRange())
+	}
+	if rule.notRegexp != nil && rule.notRegexp.MatchString(value) {
+		result = appendResult(result, NotRegxMismatch, NewNotRegxError(f.Key(), rule.notRegexp.String()),
+			f.getValueRange())
+	}
+	if rule.letterCase != nil && !inCase(value, *rule.letterCase) {
+		result = appendResult(result, CaseMismatch, NewCaseError(f.Key(), *rule.letterCase), f.getValueRange())
+	}
+	return result
+}
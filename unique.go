@@ -0,0 +1,88 @@
+package invalid
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	ConstraintKeyUnique   = `$unique`    //items must be unique, valid under type $arr
+	ConstraintKeyUniqueBy = `$unique-by` //keys of which items in type $obj must be unique, valid under type $arr
+)
+
+// newUnique read constraints of uniqueness of arr rule
+func (rule *ArrRule) newUnique() error {
+	k, v, e := GetKVNodeByKeyName(ConstraintKeyUnique, rule.getContent())
+	if k != nil && v != nil && e {
+		if !validBoolNode(v) {
+			return errors.New(fmt.Sprintf("value node must be boolean : [%s]", k.Value))
+		}
+		rule.unique = v.Value == "true"
+	}
+
+	k, v, e = GetKVNodeByKeyName(ConstraintKeyUniqueBy, rule.getContent())
+	if k != nil && v != nil && e {
+		if validStrNode(v) {
+			rule.uniqueBy = []string{v.Value}
+			return nil
+		}
+		names, err := keyNames(fmt.Sprintf("%s.%s", rule.Key(), ConstraintKeyUniqueBy), v)
+		if err != nil {
+			return err
+		}
+		if len(names) == 0 {
+			return errors.New(fmt.Sprintf("value of %s must not be empty : [%s]", ConstraintKeyUniqueBy, rule.Key()))
+		}
+		rule.uniqueBy = names
+	}
+	return nil
+}
+
+// uniqueKey return the value which item is compared by, false is returned if item is not comparable
+func (rule *ArrRule) uniqueKey(item Field) (string, bool) {
+	if rule.uniqueBy == nil {
+		if item.Kind() != FieldKindScalar {
+			return "", false
+		}
+		return item.Tag() + ":" + item.Value(), true
+	}
+
+	values := make([]string, 0, len(rule.uniqueBy))
+	for _, k := range rule.uniqueBy {
+		child, exist := item.Get(k)
+		if !exist || child.Kind() != FieldKindScalar {
+			return "", false
+		}
+		values = append(values, child.Tag()+":"+child.Value())
+	}
+	return strings.Join(values, "\x00"), true
+}
+
+// validateUnique report every item duplicating a former one in sequence field f
+func (rule *ArrRule) validateUnique(f Field, result *[]*Result) *[]*Result {
+	if (!rule.unique && rule.uniqueBy == nil) || f.Kind() != FieldKindSequence {
+		return result
+	}
+
+	first := map[string]Field{}
+	for i := 0; i < len(f.Fields()); i++ {
+		//items are visited in order of index
+		item, _ := f.Get(strconv.Itoa(i))
+		key, ok := rule.uniqueKey(item)
+		if !ok {
+			continue
+		}
+		former, exist := first[key]
+		if !exist {
+			first[key] = item
+			continue
+		}
+
+		result = appendResult(result, DuplicateItem, NewDuplicateItemError(fmt.Sprintf("%s.%s", f.Key(), item.Key()),
+			fmt.Sprintf("%s.%s", f.Key(), former.Key()), rule.uniqueBy), item.getValueRange())
+		(*result)[len(*result)-1].RelatedRange = former.getValueRange()
+	}
+	return result
+}
@@ -4,7 +4,9 @@ import (
 	"errors"
 	"fmt"
 	"gopkg.in/yaml.v3"
+	"math/big"
 	"strconv"
+	"strings"
 )
 
 //func deepFieldWithDot(keys []string) string {
@@ -76,6 +78,35 @@ func GetIntValue(key string, nodes []*yaml.Node) (int, error) {
 	return 0, errors.New(fmt.Sprintf("value not found for key : [%s]", key))
 }
 
+// parseYAMLNumber parse value of int or float node into a big float,
+// integer in hex(0xC) or octal(014, 0o14) and float in .inf are supported
+// return error when value is not a number, include .nan
+func parseYAMLNumber(tag, value string) (*big.Float, error) {
+	switch tag {
+	case yamlNodeTypeInt:
+		i, ok := new(big.Int).SetString(value, 0)
+		if !ok {
+			return nil, errors.New(fmt.Sprintf("invalid int value : [%s]", value))
+		}
+		return new(big.Float).SetInt(i), nil
+	case yamlNodeTypeFloat:
+		switch strings.ToLower(strings.TrimPrefix(value, "+")) {
+		case ".inf":
+			return new(big.Float).SetInf(false), nil
+		case "-.inf":
+			return new(big.Float).SetInf(true), nil
+		case ".nan":
+			return nil, errors.New(fmt.Sprintf("not a number : [%s]", value))
+		}
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, errors.New(fmt.Sprintf("invalid float value : [%s]", value))
+		}
+		return big.NewFloat(f), nil
+	}
+	return nil, errors.New(fmt.Sprintf("value is not a number : [%s]", value))
+}
+
 // GetStringValue get string value of content by key name
 // return error when tag mismatch
 //func GetStringValue(key string, nodes []*yaml.Node) (string, error) {
@@ -99,6 +130,16 @@ func GetKVNodeByKeyName(key string, nodes []*yaml.Node) (*yaml.Node, *yaml.Node,
 	return nil, nil, false
 }
 
+// getKVNodeInMap function return keyNode,valueNode,exist by key name, only key nodes of the mapping content are matched
+func getKVNodeInMap(key string, nodes []*yaml.Node) (*yaml.Node, *yaml.Node, bool) {
+	for i := 0; i+1 < len(nodes); i += 2 {
+		if nodes[i].Kind == yaml.ScalarNode && nodes[i].Value == key {
+			return nodes[i], nodes[i+1], true
+		}
+	}
+	return nil, nil, false
+}
+
 // weather tag of node is !!str
 func validStrNode(node *yaml.Node) bool {
 	return node.Tag == yamlNodeTypeStr
@@ -129,6 +170,11 @@ func validNullNode(node *yaml.Node) bool {
 	return node.Tag == yamlNodeTypeNull
 }
 
+// weather tag of node is !!timestamp
+func validTimestampNode(node *yaml.Node) bool {
+	return node.Tag == yamlNodeTypeTimestamp
+}
+
 // weather tag of node is !!map
 func validMapNode(node *yaml.Node) bool {
 	return node.Tag == yamlNodeTypeMap
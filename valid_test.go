@@ -1,13 +1,51 @@
 package invalid
 
 import (
+	"errors"
+	"fmt"
 	"github.com/stretchr/testify/assert"
 	"log"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
 	"testing"
 )
 
+func init() {
+	//formats and funcs used by rules in test/exam
+	_ = RegisterFormat("color", func(value string) error {
+		if !regexp.MustCompile(`^#[0-9a-fA-F]{6}$`).MatchString(value) {
+			return errors.New("must be a hex color, eg,. #ff0000")
+		}
+		return nil
+	})
+	_ = RegisterFunc("cron", func(f Field) error {
+		if n := len(strings.Fields(f.Value())); n != 5 {
+			return errors.New(fmt.Sprintf("expected 5 fields, got %d", n))
+		}
+		return nil
+	})
+	_ = RegisterFunc("even", func(f Field) error {
+		n, err := strconv.Atoi(f.Value())
+		if err != nil || n%2 != 0 {
+			return errors.New("must be an even number")
+		}
+		return nil
+	})
+	_ = RegisterFunc("window-order", func(f Field) error {
+		start, _ := f.Get("start")
+		end, _ := f.Get("end")
+		s, _ := strconv.Atoi(start.Value())
+		e, _ := strconv.Atoi(end.Value())
+		if s > e {
+			return errors.New("start must not be after end")
+		}
+		return nil
+	})
+}
+
 func TestValid(t *testing.T) {
 	yamlValid(t)
 	yamlKeyMissing(t)
@@ -15,6 +53,31 @@ func TestValid(t *testing.T) {
 	testSwagger(t)
 	constraintOfValid(t)
 	constraintOfInValid(t)
+	constraintAny(t)
+	constraintSeq(t)
+	constraintRange(t)
+	constraintKeyOf(t)
+	constraintKeyReg(t)
+	constraintStrict(t)
+	constraintPatternFields(t)
+	constraintUnion(t)
+	constraintDefinitions(t)
+	constraintImport(t)
+	constraintExtends(t)
+	constraintConditions(t)
+	constraintKeyGroups(t)
+	constraintCardinality(t)
+	constraintUnique(t)
+	constraintItems(t)
+	constraintContains(t)
+	constraintDefaults(t)
+	constraintFormat(t)
+	constraintJSONPointerRef(t)
+	constraintFunc(t)
+	constraintAssert(t)
+	constraintRefersTo(t)
+	constraintDecimals(t)
+	constraintStr(t)
 }
 
 func BenchmarkValid(b *testing.B) {
@@ -95,6 +158,823 @@ func constraintOfInValid(t *testing.T) {
 	assert.EqualValues(t, 4, len(result))
 }
 
+func constraintAny(t *testing.T) {
+	file, err := os.Open(filepath.Join([]string{"test", "yaml-cases", "any.yaml"}...))
+	assert.Nil(t, err)
+
+	field, err := NewYAML(file)
+	assert.Nil(t, err)
+	assert.NotNil(t, field)
+
+	file, err = os.OpenFile(filepath.Join("test", "exam", "any.yaml"), os.O_RDONLY, os.ModeSticky)
+	assert.Nil(t, err)
+	assert.NotNil(t, file)
+
+	rule, err := NewRule(file)
+	assert.Nil(t, err)
+	assert.NotNil(t, rule)
+
+	result := rule.Validate(field)
+	assert.EqualValues(t, 4, len(result))
+	assert.EqualValues(t, OfMismatch, result[0].Type)
+	assert.EqualValues(t, OfContainError("ofVal2", []any{"1", "on"}), result[0].Error)
+	assert.EqualValues(t, TypeMismatch, result[1].Type)
+	assert.EqualValues(t, NewTypeMismatchError("mapVal", string(RuleTypeAny)), result[1].Error)
+	assert.EqualValues(t, TypeMismatch, result[2].Type)
+	assert.EqualValues(t, NewTypeMismatchError("list.3", string(RuleTypeAny)), result[2].Error)
+	//values are compared in decoded value, eg,. True is true, and 0x1 is 1
+	assert.EqualValues(t, 4, len(result))
+	assert.EqualValues(t, OfMismatch, result[3].Type)
+	assert.EqualValues(t, OfContainError("5", []any{"true", "1", "1.5", "null"}), result[3].Error)
+}
+
+func constraintSeq(t *testing.T) {
+	file, err := os.Open(filepath.Join([]string{"test", "yaml-cases", "seq.yaml"}...))
+	assert.Nil(t, err)
+
+	field, err := NewYAML(file)
+	assert.Nil(t, err)
+	assert.NotNil(t, field)
+
+	file, err = os.OpenFile(filepath.Join("test", "exam", "seq.yaml"), os.O_RDONLY, os.ModeSticky)
+	assert.Nil(t, err)
+	assert.NotNil(t, file)
+
+	rule, err := NewRule(file)
+	assert.Nil(t, err)
+	assert.NotNil(t, rule)
+
+	result := rule.Validate(field)
+	assert.EqualValues(t, 4, len(result))
+	tried := []string{string(RuleTypeInt), string(RuleTypeStr), "$obj{foo,bar}", "$obj{name}"}
+	assert.EqualValues(t, SeqMismatch, result[0].Type)
+	assert.EqualValues(t, NewSeqMismatchError("list.3", tried), result[0].Error)
+	assert.EqualValues(t, 8, result[0].Range.Start.Line)
+	//results of the first alternative in type of element are reported
+	assert.EqualValues(t, KeyMissing, result[1].Type)
+	assert.EqualValues(t, NewKeyMissingError("bar"), result[1].Error)
+	assert.EqualValues(t, 9, result[1].Range.Start.Line)
+	assert.EqualValues(t, TypeMismatch, result[2].Type)
+	assert.EqualValues(t, NewTypeMismatchError("list3", string(RuleTypeSeq)), result[2].Error)
+	assert.EqualValues(t, TypeMismatch, result[3].Type)
+	assert.EqualValues(t, NewTypeMismatchError("2.1", string(RuleTypeInt)), result[3].Error)
+}
+
+func constraintRange(t *testing.T) {
+	file, err := os.Open(filepath.Join([]string{"test", "yaml-cases", "range.yaml"}...))
+	assert.Nil(t, err)
+
+	field, err := NewYAML(file)
+	assert.Nil(t, err)
+	assert.NotNil(t, field)
+
+	file, err = os.OpenFile(filepath.Join("test", "exam", "range.yaml"), os.O_RDONLY, os.ModeSticky)
+	assert.Nil(t, err)
+	assert.NotNil(t, file)
+
+	rule, err := NewRule(file)
+	assert.Nil(t, err)
+	assert.NotNil(t, rule)
+
+	result := rule.Validate(field)
+	assert.EqualValues(t, 5, len(result))
+	for i := range result {
+		assert.EqualValues(t, RangeMismatch, result[i].Type)
+	}
+	assert.EqualValues(t, NewRangeError("port2", "[1, 65535]"), result[0].Error)
+	assert.EqualValues(t, NewRangeError("ratio2", "(0, 1]"), result[1].Error)
+	assert.EqualValues(t, NewRangeError("octal", "(-inf, 10]"), result[2].Error)
+	assert.EqualValues(t, NewRangeError("infinity2", "[0, +inf)"), result[3].Error)
+	assert.EqualValues(t, NewRangeError("nan", "[0, +inf)"), result[4].Error)
+}
+
+func constraintKeyOf(t *testing.T) {
+	file, err := os.Open(filepath.Join([]string{"test", "yaml-cases", "key_of.yaml"}...))
+	assert.Nil(t, err)
+
+	field, err := NewYAML(file)
+	assert.Nil(t, err)
+	assert.NotNil(t, field)
+
+	file, err = os.OpenFile(filepath.Join("test", "exam", "key_of.yaml"), os.O_RDONLY, os.ModeSticky)
+	assert.Nil(t, err)
+	assert.NotNil(t, file)
+
+	rule, err := NewRule(file)
+	assert.Nil(t, err)
+	assert.NotNil(t, rule)
+
+	result := rule.Validate(field)
+	assert.EqualValues(t, 3, len(result))
+	assert.EqualValues(t, KeyOfMismatch, result[0].Type)
+	assert.EqualValues(t, NewKeyOfError("fetch", []string{"get", "post", "put"}), result[0].Error)
+	assert.EqualValues(t, 6, result[0].Range.Start.Line)
+	assert.EqualValues(t, 5, result[0].Range.Start.ColumnStart)
+	assert.EqualValues(t, TypeMismatch, result[1].Type)
+	assert.EqualValues(t, NewTypeMismatchError("summary", string(RuleTypeStr)), result[1].Error)
+	assert.EqualValues(t, KeyOfMismatch, result[2].Type)
+	assert.EqualValues(t, NewKeyOfError("500", []string{"200", "404"}), result[2].Error)
+	assert.EqualValues(t, 11, result[2].Range.Start.Line)
+}
+
+func constraintKeyReg(t *testing.T) {
+	file, err := os.Open(filepath.Join([]string{"test", "yaml-cases", "key_reg.yaml"}...))
+	assert.Nil(t, err)
+
+	field, err := NewYAML(file)
+	assert.Nil(t, err)
+	assert.NotNil(t, field)
+
+	file, err = os.OpenFile(filepath.Join("test", "exam", "key_reg.yaml"), os.O_RDONLY, os.ModeSticky)
+	assert.Nil(t, err)
+	assert.NotNil(t, file)
+
+	rule, err := NewRule(file)
+	assert.Nil(t, err)
+	assert.NotNil(t, rule)
+
+	result := rule.Validate(field)
+	assert.EqualValues(t, 1, len(result))
+	assert.EqualValues(t, KeyNameMismatch, result[0].Type)
+	assert.EqualValues(t, NewKeyNameError("logo", "^(title|version|x-.+)$"), result[0].Error)
+	assert.EqualValues(t, 6, result[0].Range.Start.Line)
+	assert.EqualValues(t, 3, result[0].Range.Start.ColumnStart)
+	assert.EqualValues(t, 7, result[0].Range.Start.ColumnEnd)
+}
+
+func constraintStrict(t *testing.T) {
+	file, err := os.Open(filepath.Join([]string{"test", "yaml-cases", "strict.yaml"}...))
+	assert.Nil(t, err)
+
+	field, err := NewYAML(file)
+	assert.Nil(t, err)
+	assert.NotNil(t, field)
+
+	file, err = os.OpenFile(filepath.Join("test", "exam", "strict.yaml"), os.O_RDONLY, os.ModeSticky)
+	assert.Nil(t, err)
+	assert.NotNil(t, file)
+
+	rule, err := NewRule(file)
+	assert.Nil(t, err)
+	assert.NotNil(t, rule)
+
+	//strict declared in rule
+	result := rule.Validate(field)
+	assert.EqualValues(t, 3, len(result))
+	assert.EqualValues(t, UnknownKey, result[0].Type)
+	assert.EqualValues(t, NewUnknownKeyError("replcas"), result[0].Error)
+	assert.EqualValues(t, 3, result[0].Range.Start.Line)
+	assert.EqualValues(t, KeyNameMismatch, result[1].Type)
+	assert.EqualValues(t, KeyOfMismatch, result[2].Type)
+
+	//strict for all rules
+	result = rule.Validate(field, WithStrict())
+	assert.EqualValues(t, 4, len(result))
+	assert.EqualValues(t, NewUnknownKeyError("replcas"), result[0].Error)
+	assert.EqualValues(t, UnknownKey, result[1].Type)
+	assert.EqualValues(t, NewUnknownKeyError("image"), result[1].Error)
+	assert.EqualValues(t, 7, result[1].Range.Start.Line)
+	assert.EqualValues(t, KeyNameMismatch, result[2].Type)
+	assert.EqualValues(t, KeyOfMismatch, result[3].Type)
+}
+
+func constraintPatternFields(t *testing.T) {
+	file, err := os.Open(filepath.Join([]string{"test", "yaml-cases", "pattern_fields.yaml"}...))
+	assert.Nil(t, err)
+
+	field, err := NewYAML(file)
+	assert.Nil(t, err)
+	assert.NotNil(t, field)
+
+	file, err = os.OpenFile(filepath.Join("test", "exam", "pattern_fields.yaml"), os.O_RDONLY, os.ModeSticky)
+	assert.Nil(t, err)
+	assert.NotNil(t, file)
+
+	rule, err := NewRule(file)
+	assert.Nil(t, err)
+	assert.NotNil(t, rule)
+
+	result := rule.Validate(field)
+	assert.EqualValues(t, 3, len(result))
+	assert.EqualValues(t, UnknownKey, result[0].Type)
+	assert.EqualValues(t, NewUnknownKeyError("logo"), result[0].Error)
+	assert.EqualValues(t, TypeMismatch, result[1].Type)
+	assert.EqualValues(t, NewTypeMismatchError("x-count", string(RuleTypeStr)), result[1].Error)
+	assert.EqualValues(t, TypeMismatch, result[2].Type)
+	assert.EqualValues(t, NewTypeMismatchError("get", string(RuleTypeStr)), result[2].Error)
+}
+
+func constraintUnion(t *testing.T) {
+	file, err := os.Open(filepath.Join([]string{"test", "yaml-cases", "union.yaml"}...))
+	assert.Nil(t, err)
+
+	field, err := NewYAML(file)
+	assert.Nil(t, err)
+	assert.NotNil(t, field)
+
+	file, err = os.OpenFile(filepath.Join("test", "exam", "union.yaml"), os.O_RDONLY, os.ModeSticky)
+	assert.Nil(t, err)
+	assert.NotNil(t, file)
+
+	rule, err := NewRule(file)
+	assert.Nil(t, err)
+	assert.NotNil(t, rule)
+
+	result := rule.Validate(field)
+	assert.EqualValues(t, 4, len(result))
+	for i := range result {
+		assert.EqualValues(t, TypeMismatch, result[i].Type)
+	}
+	assert.EqualValues(t, NewTypeMismatchError("port2", "$int | $str"), result[0].Error)
+	assert.EqualValues(t, NewTypeMismatchError("timeout2", "$int | $str"), result[1].Error)
+	assert.EqualValues(t, NewTypeMismatchError("timeout3", "$int | $str"), result[2].Error)
+	assert.EqualValues(t, NewTypeMismatchError("tls2", "$bool | $obj"), result[3].Error)
+}
+
+func constraintDefinitions(t *testing.T) {
+	file, err := os.Open(filepath.Join([]string{"test", "yaml-cases", "definitions.yaml"}...))
+	assert.Nil(t, err)
+
+	field, err := NewYAML(file)
+	assert.Nil(t, err)
+	assert.NotNil(t, field)
+
+	file, err = os.OpenFile(filepath.Join("test", "exam", "definitions.yaml"), os.O_RDONLY, os.ModeSticky)
+	assert.Nil(t, err)
+	assert.NotNil(t, file)
+
+	rule, err := NewRule(file)
+	assert.Nil(t, err)
+	assert.NotNil(t, rule)
+
+	result := rule.Validate(field)
+	assert.EqualValues(t, 2, len(result))
+	assert.EqualValues(t, TypeMismatch, result[0].Type)
+	assert.EqualValues(t, NewTypeMismatchError("name", string(RuleTypeStr)), result[0].Error)
+	assert.EqualValues(t, 8, result[0].Range.Start.Line)
+	assert.EqualValues(t, RegxMismatch, result[1].Type)
+	assert.EqualValues(t, NewRegxError("home", "^/"), result[1].Error)
+}
+
+func constraintImport(t *testing.T) {
+	file, err := os.Open(filepath.Join([]string{"test", "yaml-cases", "import.yaml"}...))
+	assert.Nil(t, err)
+
+	field, err := NewYAML(file)
+	assert.Nil(t, err)
+	assert.NotNil(t, field)
+
+	rule, err := NewRuleFS(os.DirFS(filepath.Join("test", "exam", "import")), "service.yaml")
+	assert.Nil(t, err)
+	assert.NotNil(t, rule)
+
+	result := rule.Validate(field)
+	assert.EqualValues(t, 1, len(result))
+	assert.EqualValues(t, KeyNameMismatch, result[0].Type)
+	assert.EqualValues(t, NewKeyNameError("tier", "^app"), result[0].Error)
+}
+
+func constraintExtends(t *testing.T) {
+	file, err := os.Open(filepath.Join([]string{"test", "yaml-cases", "extends.yaml"}...))
+	assert.Nil(t, err)
+
+	field, err := NewYAML(file)
+	assert.Nil(t, err)
+	assert.NotNil(t, field)
+
+	file, err = os.OpenFile(filepath.Join("test", "exam", "extends.yaml"), os.O_RDONLY, os.ModeSticky)
+	assert.Nil(t, err)
+	assert.NotNil(t, file)
+
+	rule, err := NewRule(file)
+	assert.Nil(t, err)
+	assert.NotNil(t, rule)
+
+	result := rule.Validate(field)
+	assert.EqualValues(t, 3, len(result))
+	assert.EqualValues(t, OfMismatch, result[0].Type)
+	assert.EqualValues(t, OfContainError("strategy", []any{"RollingUpdate"}), result[0].Error)
+	assert.EqualValues(t, StrLengthMismatch, result[1].Type)
+	assert.EqualValues(t, NewStrLengthError2("name", 20), result[1].Error)
+	assert.EqualValues(t, RangeMismatch, result[2].Type)
+	assert.EqualValues(t, NewRangeError("timeout", "(1, 30]"), result[2].Error)
+}
+
+func constraintConditions(t *testing.T) {
+	file, err := os.Open(filepath.Join([]string{"test", "yaml-cases", "conditions.yaml"}...))
+	assert.Nil(t, err)
+
+	field, err := NewYAML(file)
+	assert.Nil(t, err)
+	assert.NotNil(t, field)
+
+	file, err = os.OpenFile(filepath.Join("test", "exam", "conditions.yaml"), os.O_RDONLY, os.ModeSticky)
+	assert.Nil(t, err)
+	assert.NotNil(t, file)
+
+	rule, err := NewRule(file)
+	assert.Nil(t, err)
+	assert.NotNil(t, rule)
+
+	result := rule.Validate(field)
+	assert.EqualValues(t, 4, len(result))
+	assert.EqualValues(t, OfMismatch, result[0].Type)
+	assert.EqualValues(t, NewConditionalError(OfContainError("externalTrafficPolicy", []any{"Cluster", "Local"}),
+		"type in [NodePort LoadBalancer]"), result[0].Error)
+	assert.EqualValues(t, KeyForbidden, result[1].Type)
+	assert.EqualValues(t, NewConditionalError(NewKeyForbiddenError("nodePort"), "not (type == NodePort)"),
+		result[1].Error)
+	assert.EqualValues(t, 8, result[1].Range.Start.Line)
+	assert.EqualValues(t, OfMismatch, result[2].Type)
+	assert.EqualValues(t, NewConditionalError(OfContainError("sessionAffinity", []any{"ClientIP"}),
+		"sessionAffinityConfig exists"), result[2].Error)
+	assert.EqualValues(t, KeyMissing, result[3].Type)
+	assert.EqualValues(t, NewConditionalError(NewKeyMissingError("loadBalancerIP"), "type == LoadBalancer"),
+		result[3].Error)
+}
+
+func constraintKeyGroups(t *testing.T) {
+	file, err := os.Open(filepath.Join([]string{"test", "yaml-cases", "key_groups.yaml"}...))
+	assert.Nil(t, err)
+
+	field, err := NewYAML(file)
+	assert.Nil(t, err)
+	assert.NotNil(t, field)
+
+	file, err = os.OpenFile(filepath.Join("test", "exam", "key_groups.yaml"), os.O_RDONLY, os.ModeSticky)
+	assert.Nil(t, err)
+	assert.NotNil(t, file)
+
+	rule, err := NewRule(file)
+	assert.Nil(t, err)
+	assert.NotNil(t, rule)
+
+	result := rule.Validate(field)
+	assert.EqualValues(t, 5, len(result))
+	assert.EqualValues(t, OneOfKeysMismatch, result[0].Type)
+	assert.EqualValues(t, NewOneOfKeysError([]string{"image", "build"}, []string{}), result[0].Error)
+	assert.EqualValues(t, NoneOfKeysMismatch, result[1].Type)
+	assert.EqualValues(t, NewNoneOfKeysError([]string{"links", "external_links"}, []string{"links"}), result[1].Error)
+	assert.EqualValues(t, DependencyMissing, result[2].Type)
+	assert.EqualValues(t, NewDependencyError("tls", []string{"keyFile"}), result[2].Error)
+	assert.EqualValues(t, OneOfKeysMismatch, result[3].Type)
+	assert.EqualValues(t, NewOneOfKeysError([]string{"image", "build"}, []string{"image", "build"}), result[3].Error)
+	assert.EqualValues(t, AnyOfKeysMismatch, result[4].Type)
+	assert.EqualValues(t, NewAnyOfKeysError([]string{"command", "entrypoint"}), result[4].Error)
+
+	//range covers the parent mapping
+	services, _ := field.Get("services")
+	web, _ := services.Get("web")
+	assert.Equal(t, web.ValueRange(), result[2].Range)
+}
+
+func constraintCardinality(t *testing.T) {
+	file, err := os.Open(filepath.Join([]string{"test", "yaml-cases", "cardinality.yaml"}...))
+	assert.Nil(t, err)
+
+	field, err := NewYAML(file)
+	assert.Nil(t, err)
+	assert.NotNil(t, field)
+
+	file, err = os.OpenFile(filepath.Join("test", "exam", "cardinality.yaml"), os.O_RDONLY, os.ModeSticky)
+	assert.Nil(t, err)
+	assert.NotNil(t, file)
+
+	rule, err := NewRule(file)
+	assert.Nil(t, err)
+	assert.NotNil(t, rule)
+
+	result := rule.Validate(field)
+	assert.EqualValues(t, 5, len(result))
+	assert.EqualValues(t, ItemCountMismatch, result[0].Type)
+	assert.EqualValues(t, NewItemCountError("containers", 0, "at least 1"), result[0].Error)
+	assert.EqualValues(t, ItemCountMismatch, result[1].Type)
+	assert.EqualValues(t, NewItemCountError("tags", 3, "at most 2"), result[1].Error)
+	assert.EqualValues(t, KeyCountMismatch, result[2].Type)
+	assert.EqualValues(t, NewKeyCountError("labels", 3, "at most 2"), result[2].Error)
+	assert.EqualValues(t, KeyCountMismatch, result[3].Type)
+	assert.EqualValues(t, NewKeyCountError("annotations", 0, "at least 1"), result[3].Error)
+	//value not in a list is not counted
+	assert.EqualValues(t, TypeMismatch, result[4].Type)
+	assert.EqualValues(t, NewTypeMismatchError("hosts", string(RuleTypeArr)), result[4].Error)
+
+	//range covers the whole list
+	tags, _ := field.Get("tags")
+	assert.Equal(t, tags.ValueRange(), result[1].Range)
+	assert.EqualValues(t, 4, result[1].Range.Start.Line)
+	assert.EqualValues(t, 6, result[1].Range.End.Line)
+}
+
+func constraintUnique(t *testing.T) {
+	file, err := os.Open(filepath.Join([]string{"test", "yaml-cases", "unique.yaml"}...))
+	assert.Nil(t, err)
+
+	field, err := NewYAML(file)
+	assert.Nil(t, err)
+	assert.NotNil(t, field)
+
+	file, err = os.OpenFile(filepath.Join("test", "exam", "unique.yaml"), os.O_RDONLY, os.ModeSticky)
+	assert.Nil(t, err)
+	assert.NotNil(t, file)
+
+	rule, err := NewRule(file)
+	assert.Nil(t, err)
+	assert.NotNil(t, rule)
+
+	result := rule.Validate(field)
+	assert.EqualValues(t, 2, len(result))
+	assert.EqualValues(t, DuplicateItem, result[0].Type)
+	assert.EqualValues(t, NewDuplicateItemError("ports.3", "ports.0", nil), result[0].Error)
+	assert.EqualValues(t, 6, result[0].Range.Start.Line)
+	assert.EqualValues(t, 3, result[0].RelatedRange.Start.Line)
+	assert.EqualValues(t, DuplicateItem, result[1].Type)
+	assert.EqualValues(t, NewDuplicateItemError("containers.2", "containers.0", []string{"name"}), result[1].Error)
+	assert.EqualValues(t, 12, result[1].Range.Start.Line)
+	assert.EqualValues(t, 8, result[1].RelatedRange.Start.Line)
+}
+
+func constraintItems(t *testing.T) {
+	file, err := os.Open(filepath.Join([]string{"test", "yaml-cases", "items.yaml"}...))
+	assert.Nil(t, err)
+
+	field, err := NewYAML(file)
+	assert.Nil(t, err)
+	assert.NotNil(t, field)
+
+	file, err = os.OpenFile(filepath.Join("test", "exam", "items.yaml"), os.O_RDONLY, os.ModeSticky)
+	assert.Nil(t, err)
+	assert.NotNil(t, file)
+
+	rule, err := NewRule(file)
+	assert.Nil(t, err)
+	assert.NotNil(t, rule)
+
+	result := rule.Validate(field)
+	assert.EqualValues(t, 4, len(result))
+	assert.EqualValues(t, TypeMismatch, result[0].Type)
+	assert.EqualValues(t, NewTypeMismatchError("backends.2", string(RuleTypeInt)), result[0].Error)
+	assert.EqualValues(t, 5, result[0].Range.Start.Line)
+	assert.EqualValues(t, AdditionalItems, result[1].Type)
+	assert.EqualValues(t, NewAdditionalItemError("backends.3", 3), result[1].Error)
+	assert.EqualValues(t, 6, result[1].Range.Start.Line)
+	assert.EqualValues(t, ItemMissing, result[2].Type)
+	assert.EqualValues(t, NewItemMissingError("fallback.1"), result[2].Error)
+	assert.EqualValues(t, TypeMismatch, result[3].Type)
+	assert.EqualValues(t, NewTypeMismatchError("mirror.2", string(RuleTypeInt)), result[3].Error)
+}
+
+func constraintContains(t *testing.T) {
+	file, err := os.Open(filepath.Join([]string{"test", "yaml-cases", "contains.yaml"}...))
+	assert.Nil(t, err)
+
+	field, err := NewYAML(file)
+	assert.Nil(t, err)
+	assert.NotNil(t, field)
+
+	file, err = os.OpenFile(filepath.Join("test", "exam", "contains.yaml"), os.O_RDONLY, os.ModeSticky)
+	assert.Nil(t, err)
+	assert.NotNil(t, file)
+
+	rule, err := NewRule(file)
+	assert.Nil(t, err)
+	assert.NotNil(t, rule)
+
+	result := rule.Validate(field)
+	assert.EqualValues(t, 3, len(result))
+	assert.EqualValues(t, ContainsMismatch, result[0].Type)
+	assert.EqualValues(t, NewContainsError("env", 0, "at least 1"), result[0].Error)
+	assert.EqualValues(t, ContainsMismatch, result[1].Type)
+	assert.EqualValues(t, NewContainsError("args", 2, "at most 1"), result[1].Error)
+	assert.EqualValues(t, ContainsMismatch, result[2].Type)
+	assert.EqualValues(t, NewContainsError("ports", 1, "at least 2"), result[2].Error)
+
+	//range covers the whole list
+	env, _ := field.Get("env")
+	assert.Equal(t, env.ValueRange(), result[0].Range)
+}
+
+func constraintDefaults(t *testing.T) {
+	file, err := os.Open(filepath.Join([]string{"test", "yaml-cases", "defaults.yaml"}...))
+	assert.Nil(t, err)
+
+	field, err := NewYAML(file)
+	assert.Nil(t, err)
+	assert.NotNil(t, field)
+
+	file, err = os.OpenFile(filepath.Join("test", "exam", "defaults.yaml"), os.O_RDONLY, os.ModeSticky)
+	assert.Nil(t, err)
+	assert.NotNil(t, file)
+
+	rule, err := NewRule(file)
+	assert.Nil(t, err)
+	assert.NotNil(t, rule)
+
+	applied, err := ApplyDefaults(rule, field)
+	assert.Nil(t, err)
+	assert.EqualValues(t, 0, len(rule.Validate(applied)))
+
+	server, _ := applied.Get("server")
+	host, _ := server.Get("host")
+	assert.EqualValues(t, "0.0.0.0", host.Value())
+	port, _ := server.Get("port")
+	assert.EqualValues(t, "9090", port.Value())
+	tls, _ := server.Get("tls")
+	minVersion, _ := tls.Get("minVersion")
+	assert.EqualValues(t, ValueTypeStr, minVersion.ValueType())
+
+	b, err := MarshalYAML(applied)
+	assert.Nil(t, err)
+	assert.EqualValues(t, `server:
+  port: 9090
+  host: 0.0.0.0
+  tls:
+    enabled: false
+    minVersion: "1.2"
+backends:
+  - url: http://a
+    weight: 1
+  - url: http://b
+    weight: 3
+services:
+  - type: LoadBalancer
+    timeout: 30
+  - type: ClusterIP
+endpoint: [example.com, 80]
+menu:
+  name: root
+  children:
+    - name: home
+`, string(b))
+
+	//source field is not modified
+	server, _ = field.Get("server")
+	_, exist := server.Get("host")
+	assert.False(t, exist)
+}
+
+func constraintFormat(t *testing.T) {
+	file, err := os.Open(filepath.Join([]string{"test", "yaml-cases", "format.yaml"}...))
+	assert.Nil(t, err)
+
+	field, err := NewYAML(file)
+	assert.Nil(t, err)
+	assert.NotNil(t, field)
+
+	file, err = os.OpenFile(filepath.Join("test", "exam", "format.yaml"), os.O_RDONLY, os.ModeSticky)
+	assert.Nil(t, err)
+	assert.NotNil(t, file)
+
+	rule, err := NewRule(file)
+	assert.Nil(t, err)
+	assert.NotNil(t, rule)
+
+	result := rule.Validate(field)
+	assert.EqualValues(t, 5, len(result))
+	for i := range result {
+		assert.EqualValues(t, FormatMismatch, result[i].Type)
+	}
+	assert.EqualValues(t, NewFormatError("homepage", FormatURI,
+		errors.New("must be an absolute URI with scheme, eg,. https://example.com/path")), result[0].Error)
+	assert.EqualValues(t, NewFormatError("address", FormatIPv4,
+		errors.New("must be an IPv4 address, eg,. 192.168.0.1")), result[1].Error)
+	assert.EqualValues(t, NewFormatError("host", FormatHostname,
+		errors.New("must be a hostname, label [-api] is invalid")), result[2].Error)
+	assert.EqualValues(t, NewFormatError("version", FormatSemver,
+		errors.New("must be a semantic version, eg,. 1.2.3 or 1.0.0-rc.1")), result[3].Error)
+	assert.EqualValues(t, NewFormatError("color", "color", errors.New("must be a hex color, eg,. #ff0000")),
+		result[4].Error)
+}
+
+func constraintJSONPointerRef(t *testing.T) {
+	file, err := os.Open(filepath.Join([]string{"test", "yaml-cases", "json_pointer_ref.yaml"}...))
+	assert.Nil(t, err)
+
+	field, err := NewYAML(file)
+	assert.Nil(t, err)
+	assert.NotNil(t, field)
+
+	file, err = os.OpenFile(filepath.Join("test", "exam", "json_pointer_ref.yaml"), os.O_RDONLY, os.ModeSticky)
+	assert.Nil(t, err)
+	assert.NotNil(t, file)
+
+	rule, err := NewRule(file)
+	assert.Nil(t, err)
+	assert.NotNil(t, rule)
+
+	//file refs are not resolved without base directory
+	result := rule.Validate(field)
+	assert.EqualValues(t, 2, len(result))
+	assert.EqualValues(t, RefUnresolved, result[0].Type)
+	assert.EqualValues(t, NewRefUnresolvedError("2", "#/components/responses/Pett",
+		NewPointerKeyError("Pett", "#/components/responses")), result[0].Error)
+	assert.EqualValues(t, 4, result[0].Range.Start.Line)
+	assert.EqualValues(t, FormatMismatch, result[1].Type)
+	assert.EqualValues(t, NewFormatError("7", FormatJSONPointerRef,
+		errors.New("must be a JSON pointer reference, pointer must start with /")), result[1].Error)
+
+	result = rule.Validate(field, WithBaseDir(filepath.Join("test", "yaml-cases")))
+	assert.EqualValues(t, 6, len(result))
+	assert.EqualValues(t, RefUnresolved, result[1].Type)
+	assert.EqualValues(t, NewRefUnresolvedError("4", "refs/errors.yaml#/responses/Internal",
+		NewPointerKeyError("Internal", "refs/errors.yaml#/responses")), result[1].Error)
+	assert.EqualValues(t, 6, result[1].Range.Start.Line)
+	assert.EqualValues(t, RefUnresolved, result[2].Type)
+	assert.EqualValues(t, NewRefUnresolvedError("5", "refs/missing.yaml#/responses/Unavailable",
+		errors.New("file [refs/missing.yaml] is not found")), result[2].Error)
+	assert.EqualValues(t, FormatMismatch, result[3].Type)
+
+	//files outside of base directory are not read
+	assert.EqualValues(t, NewRefUnresolvedError("0", "../exam/format.yaml#/contact",
+		errors.New("file [../exam/format.yaml] is outside of base directory")), result[4].Error)
+	assert.EqualValues(t, NewRefUnresolvedError("1", "/etc/hostname#",
+		errors.New("file [/etc/hostname] is outside of base directory")), result[5].Error)
+}
+
+func constraintFunc(t *testing.T) {
+	file, err := os.Open(filepath.Join([]string{"test", "yaml-cases", "func.yaml"}...))
+	assert.Nil(t, err)
+
+	field, err := NewYAML(file)
+	assert.Nil(t, err)
+	assert.NotNil(t, field)
+
+	file, err = os.OpenFile(filepath.Join("test", "exam", "func.yaml"), os.O_RDONLY, os.ModeSticky)
+	assert.Nil(t, err)
+	assert.NotNil(t, file)
+
+	rule, err := NewRule(file)
+	assert.Nil(t, err)
+	assert.NotNil(t, rule)
+
+	result := rule.Validate(field)
+	assert.EqualValues(t, 5, len(result))
+	assert.EqualValues(t, FuncMismatch, result[0].Type)
+	assert.EqualValues(t, NewFuncError("schedule", "cron", errors.New("expected 5 fields, got 4")), result[0].Error)
+	assert.EqualValues(t, 2, result[0].Range.Start.Line)
+	assert.EqualValues(t, FuncMismatch, result[1].Type)
+	assert.EqualValues(t, NewFuncError("replicas", "even", errors.New("must be an even number")), result[1].Error)
+	assert.EqualValues(t, FuncMismatch, result[2].Type)
+	assert.EqualValues(t, NewFuncError("schedule", "cron", errors.New("expected 5 fields, got 1")), result[2].Error)
+	assert.EqualValues(t, 6, result[2].Range.Start.Line)
+	assert.EqualValues(t, FuncMismatch, result[3].Type)
+	assert.EqualValues(t, NewFuncError("window", "window-order", errors.New("start must not be after end")),
+		result[3].Error)
+
+	//func is not called while field fails the other constraints
+	assert.EqualValues(t, TypeMismatch, result[4].Type)
+	assert.EqualValues(t, NewTypeMismatchError("backup", string(RuleTypeStr)), result[4].Error)
+}
+
+func constraintAssert(t *testing.T) {
+	file, err := os.Open(filepath.Join([]string{"test", "yaml-cases", "assert.yaml"}...))
+	assert.Nil(t, err)
+
+	field, err := NewYAML(file)
+	assert.Nil(t, err)
+	assert.NotNil(t, field)
+
+	file, err = os.OpenFile(filepath.Join("test", "exam", "assert.yaml"), os.O_RDONLY, os.ModeSticky)
+	assert.Nil(t, err)
+	assert.NotNil(t, file)
+
+	rule, err := NewRule(file)
+	assert.Nil(t, err)
+	assert.NotNil(t, rule)
+
+	result := rule.Validate(field)
+	assert.EqualValues(t, 6, len(result))
+	for i := range result {
+		assert.EqualValues(t, AssertMismatch, result[i].Type)
+	}
+	assert.EqualValues(t, NewAssertError("replicas <= maxReplicas", nil), result[0].Error)
+	assert.EqualValues(t, NewAssertError("endDate > startDate", nil), result[1].Error)
+	assert.EqualValues(t, NewAssertError("budget.daily * 30 <= budget.total", nil), result[2].Error)
+	//path missing is reported, unless it's tested by has, eg,. autoscale
+	assert.EqualValues(t, NewAssertError("replicas <= maxReplicas", NewPathMissingError("maxReplicas")),
+		result[3].Error)
+	assert.EqualValues(t, NewAssertError("replicas > 0",
+		errors.New("operator [>] is not applicable to string and number")), result[4].Error)
+	//unquoted dates are compared as timestamps
+	assert.EqualValues(t, NewAssertError("endDate > startDate", nil), result[5].Error)
+
+	//range covers the mapping
+	deployment, _ := field.Get("deployment")
+	assert.Equal(t, deployment.ValueRange(), result[0].Range)
+}
+
+func constraintRefersTo(t *testing.T) {
+	file, err := os.Open(filepath.Join([]string{"test", "yaml-cases", "refers_to.yaml"}...))
+	assert.Nil(t, err)
+
+	field, err := NewYAML(file)
+	assert.Nil(t, err)
+	assert.NotNil(t, field)
+
+	file, err = os.OpenFile(filepath.Join("test", "exam", "refers_to.yaml"), os.O_RDONLY, os.ModeSticky)
+	assert.Nil(t, err)
+	assert.NotNil(t, file)
+
+	rule, err := NewRule(file)
+	assert.Nil(t, err)
+	assert.NotNil(t, rule)
+
+	result := rule.Validate(field)
+	assert.EqualValues(t, 4, len(result))
+	for i := range result {
+		assert.EqualValues(t, RefersToMismatch, result[i].Type)
+	}
+
+	//matchLabels must be a subset of labels
+	labels := field
+	for _, k := range []string{"deployment", "spec", "template", "metadata", "labels"} {
+		labels, _ = labels.Get(k)
+	}
+	assert.EqualValues(t, NewRefersToError("matchLabels", "tier: backend", ReferAsEntry,
+		"deployment.spec.template.metadata.labels"), result[0].Error)
+	assert.EqualValues(t, 6, result[0].Range.Start.Line)
+	assert.Equal(t, labels.ValueRange(), result[0].RelatedRange)
+
+	//targetPort must be name of a container port
+	assert.EqualValues(t, NewRefersToError("targetPort", "https", ReferAsValue,
+		"deployment.spec.template.spec.containers.*.ports.*.name"), result[1].Error)
+	assert.EqualValues(t, 28, result[1].Range.Start.Line)
+	assert.EqualValues(t, 16, result[1].RelatedRange.Start.Line)
+	assert.EqualValues(t, 20, result[1].RelatedRange.End.Line)
+
+	assert.EqualValues(t, NewRefersToError("groupBy", "zone", ReferAsKey,
+		"deployment.spec.template.metadata.labels"), result[2].Error)
+	assert.EqualValues(t, 31, result[2].Range.Start.Line)
+
+	//rule in $use of a definition
+	assert.EqualValues(t, NewRefersToError("podSelector", "tier: backend", ReferAsEntry,
+		"deployment.spec.template.metadata.labels"), result[3].Error)
+	assert.EqualValues(t, 34, result[3].Range.Start.Line)
+}
+
+func constraintDecimals(t *testing.T) {
+	file, err := os.Open(filepath.Join([]string{"test", "yaml-cases", "decimals.yaml"}...))
+	assert.Nil(t, err)
+
+	field, err := NewYAML(file)
+	assert.Nil(t, err)
+	assert.NotNil(t, field)
+
+	file, err = os.OpenFile(filepath.Join("test", "exam", "decimals.yaml"), os.O_RDONLY, os.ModeSticky)
+	assert.Nil(t, err)
+	assert.NotNil(t, file)
+
+	rule, err := NewRule(file)
+	assert.Nil(t, err)
+	assert.NotNil(t, rule)
+
+	//0.3 is a multiple of 0.1 and 19.90 is within 2 decimal places in exact value
+	result := rule.Validate(field)
+	assert.EqualValues(t, 4, len(result))
+	assert.EqualValues(t, MultipleOfMismatch, result[0].Type)
+	assert.EqualValues(t, NewMultipleOfError("cpuShares", "128"), result[0].Error)
+	assert.EqualValues(t, 6, result[0].Range.Start.Line)
+	assert.EqualValues(t, DecimalsMismatch, result[1].Type)
+	assert.EqualValues(t, NewMaxDecimalsError("price", 2), result[1].Error)
+	assert.EqualValues(t, MultipleOfMismatch, result[2].Type)
+	assert.EqualValues(t, NewMultipleOfError("step", "0.1"), result[2].Error)
+	assert.EqualValues(t, DecimalsMismatch, result[3].Type)
+	assert.EqualValues(t, NewMaxDecimalsError("weight", 1), result[3].Error)
+}
+
+func constraintStr(t *testing.T) {
+	file, err := os.Open(filepath.Join([]string{"test", "yaml-cases", "str.yaml"}...))
+	assert.Nil(t, err)
+
+	field, err := NewYAML(file)
+	assert.Nil(t, err)
+	assert.NotNil(t, field)
+
+	file, err = os.OpenFile(filepath.Join("test", "exam", "str.yaml"), os.O_RDONLY, os.ModeSticky)
+	assert.Nil(t, err)
+	assert.NotNil(t, file)
+
+	rule, err := NewRule(file)
+	assert.Nil(t, err)
+	assert.NotNil(t, rule)
+
+	result := rule.Validate(field)
+	assert.EqualValues(t, 6, len(result))
+	assert.EqualValues(t, CaseMismatch, result[0].Type)
+	assert.EqualValues(t, NewCaseError("name", CaseKebab), result[0].Error)
+	assert.EqualValues(t, 6, result[0].Range.Start.Line)
+	assert.EqualValues(t, PrefixMismatch, result[1].Type)
+	assert.EqualValues(t, NewPrefixError("image", "registry.example.com/"), result[1].Error)
+	assert.EqualValues(t, NotRegxMismatch, result[2].Type)
+	assert.EqualValues(t, NewNotRegxError("image", ":latest$"), result[2].Error)
+	assert.EqualValues(t, SuffixMismatch, result[3].Type)
+	assert.EqualValues(t, NewSuffixError("config", ".yaml"), result[3].Error)
+	assert.EqualValues(t, CaseMismatch, result[4].Type)
+	assert.EqualValues(t, NewCaseError("env", CaseUpper), result[4].Error)
+	assert.EqualValues(t, SubstringMismatch, result[5].Type)
+	assert.EqualValues(t, NewSubstringError("image", ":"), result[5].Error)
+	assert.EqualValues(t, 11, result[5].Range.Start.Line)
+}
+
 func constraintOfValid(t *testing.T) {
 	file, err := os.OpenFile(filepath.Join("test", "exam", "constraint_of.yaml"), os.O_RDONLY, os.ModeSticky)
 	assert.Nil(t, err)
@@ -190,4 +1070,7 @@ func yamlKeyMissing(t *testing.T) {
 	assert.NotNil(t, errs)
 	assert.EqualValues(t, 1, len(errs))
 	assert.EqualValues(t, NewKeyMissingError("bar1"), errs[0].Error)
+	//range of mapping where key is missing
+	assert.EqualValues(t, 5, errs[0].Range.Start.Line)
+	assert.EqualValues(t, 6, errs[0].Range.End.Line)
 }
\ No newline at end of file
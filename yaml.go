@@ -38,6 +38,7 @@ const (
 type Field interface {
 	restructure(sibling *yaml.Node) error
 	getValueRange() *Range
+	getValueNode() *yaml.Node
 	Key() string
 	setKey(key string)
 	Value() string
@@ -137,6 +138,11 @@ func NewYamlField(keyNode, valueNode *yaml.Node) (Field, error) {
 			keyNode:   keyNode,
 			valueNode: valueNode,
 		}}
+	} else if validTimestampNode(valueNode) {
+		fieldInt = &YAMLTimestampField{YAMLField{
+			keyNode:   keyNode,
+			valueNode: valueNode,
+		}}
 	}
 
 	return fieldInt, nil
@@ -175,6 +181,10 @@ func (f *YAMLField) restructure(sibling *yaml.Node) error {
 	return nil
 }
 
+func (f *YAMLField) getValueNode() *yaml.Node {
+	return f.valueNode
+}
+
 func (f *YAMLField) Key() string {
 	return f.key
 }
@@ -521,3 +531,16 @@ func (field *YAMLNilField) restructure(sibling *yaml.Node) error {
 	}
 	return nil
 }
+
+// YAMLTimestampField timestamp field for YAML, eg,. an unquoted date 2023-06-30
+type YAMLTimestampField struct {
+	YAMLField
+}
+
+func (field *YAMLTimestampField) restructure(sibling *yaml.Node) error {
+	err := field.YAMLField.restructure(sibling)
+	if err != nil {
+		return err
+	}
+	return nil
+}